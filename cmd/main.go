@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 
 	"github.com/Modalessi/nuha-api/internal/database"
 	"github.com/Modalessi/nuha-api/internal/judgeAPI"
@@ -33,20 +36,76 @@ func main() {
 
 	dbQueries := database.New(db)
 
-	rapidAPIKey := os.Getenv("X_RAPIDAPI_KEY")
-	utils.AssertOn(rapidAPIKey != "", "somethign went wrong when reading 'X_RAPIDAPI_KEY' env variable")
-
-	rapidAPIHost := os.Getenv("X_RAPIDAPI_HOST")
-	utils.AssertOn(rapidAPIKey != "", "somethign went wrong when reading 'X_RAPIDAPI_HOST' env variable")
-
-	judgeAPI := judgeAPI.NewJudgeAPI(rapidAPIKey, rapidAPIHost)
+	judge := newJudge()
 
 	adminEmail := os.Getenv("ADMIN_EMAIL")
 	utils.AssertOn(adminEmail != "", "somethign went wrong when reading 'ADMIN_EMAIL' env variable")
 
-	nuhaServer := nuha.NewServer(judgeAPI, db, dbQueries, JWTSecret, adminEmail)
+	serverConfig := nuha.ServerConfig{
+		JWTSecret:            JWTSecret,
+		AdminEmail:           adminEmail,
+		JudgeCallbackBaseURL: os.Getenv("JUDGE_CALLBACK_BASE_URL"),
+		JudgeCallbackSecret:  os.Getenv("JUDGE_CALLBACK_SECRET"),
+	}
+	if serverConfig.JudgeCallbackBaseURL != "" {
+		utils.AssertOn(serverConfig.JudgeCallbackSecret != "", "'JUDGE_CALLBACK_SECRET' env variable is required when 'JUDGE_CALLBACK_BASE_URL' is set")
+	}
+	if runsEnv := os.Getenv("RUNS_PER_MINUTE"); runsEnv != "" {
+		n, err := strconv.Atoi(runsEnv)
+		utils.AssertOn(err == nil && n > 0, "'RUNS_PER_MINUTE' env variable must be a positive number")
+		serverConfig.RunsPerMinute = n
+	}
+
+	nuhaServer := nuha.NewServer(judge, db, dbQueries, serverConfig)
 
 	// TODO: gracful shut down for SIGINT, SIGTERM
 	fmt.Println("server is now running...")
 	http.ListenAndServe("localhost"+ADDRESS, nuhaServer.GetServer())
 }
+
+// newJudge picks the judge backend from the 'JUDGE_BACKEND' env variable,
+// rapidapi is the default so old .env files keep working
+func newJudge() judgeAPI.Judge {
+	backend := os.Getenv("JUDGE_BACKEND")
+
+	switch backend {
+	case "", "rapidapi":
+		rapidAPIKey := os.Getenv("X_RAPIDAPI_KEY")
+		utils.AssertOn(rapidAPIKey != "", "somethign went wrong when reading 'X_RAPIDAPI_KEY' env variable")
+
+		rapidAPIHost := os.Getenv("X_RAPIDAPI_HOST")
+		utils.AssertOn(rapidAPIHost != "", "somethign went wrong when reading 'X_RAPIDAPI_HOST' env variable")
+
+		return judgeAPI.NewJudgeAPI(rapidAPIKey, rapidAPIHost)
+
+	case "selfhosted":
+		judge0URL := os.Getenv("JUDGE0_URL")
+		utils.AssertOn(judge0URL != "", "somethign went wrong when reading 'JUDGE0_URL' env variable")
+
+		judge, err := judgeAPI.NewSelfHostedJudgeAPI(judge0URL, os.Getenv("JUDGE0_AUTH_TOKEN"), os.Getenv("JUDGE0_AUTH_USER"))
+		utils.Assert(err, "error creating self hosted judge zero client")
+
+		return judge
+
+	case "local":
+		workDir := os.Getenv("LOCAL_JUDGE_DIR")
+		if workDir == "" {
+			workDir = filepath.Join(os.TempDir(), "nuha-judge")
+		}
+
+		workers := runtime.NumCPU()
+		if workersEnv := os.Getenv("LOCAL_JUDGE_WORKERS"); workersEnv != "" {
+			n, err := strconv.Atoi(workersEnv)
+			utils.AssertOn(err == nil && n > 0, "'LOCAL_JUDGE_WORKERS' env variable must be a positive number")
+			workers = n
+		}
+
+		judge, err := judgeAPI.NewLocalJudge(workDir, workers)
+		utils.Assert(err, "error creating local judge")
+
+		return judge
+
+	default:
+		panic(fmt.Sprintf("unknown 'JUDGE_BACKEND' %q, must be one of (rapidapi, selfhosted, local)", backend))
+	}
+}
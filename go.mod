@@ -1,6 +1,6 @@
 module github.com/Modalessi/nuha-api
 
-go 1.22.5
+go 1.24
 
 require (
 	github.com/joho/godotenv v1.5.1
@@ -5,11 +5,35 @@
 package database
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type JudgeJob struct {
+	ID             uuid.UUID
+	SubmissionID   uuid.UUID
+	State          string
+	Tokens         []string
+	Attempts       int32
+	LeaseExpiresAt sql.NullTime
+	LastError      string
+	UpdatedAt      time.Time
+	CreatedAt      time.Time
+	LastResponse   string
+	UserID         uuid.UUID
+}
+
+type LanguageSetting struct {
+	Language        int32
+	TimeMultiplier  float64
+	ExtraMemory     float64
+	CompilerOptions string
+	UpdatedAt       time.Time
+	CreatedAt       time.Time
+}
+
 type PasswordResetToken struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
@@ -20,14 +44,47 @@ type PasswordResetToken struct {
 }
 
 type Problem struct {
-	ID          uuid.UUID
-	Title       string
-	Difficulty  string
-	Tags        []string
-	TimeLimit   float64
-	MemoryLimit float64
-	CreatedAt   time.Time
+	ID                uuid.UUID
+	Title             string
+	Difficulty        string
+	Tags              []string
+	TimeLimit         float64
+	MemoryLimit       float64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	JudgingMode       string
+	ScoringMode       string
+	ComparisonMode    string
+	ComparisonEpsilon float64
+	ProblemType       string
+	FunctionSignature string
+}
+
+type ProblemChecker struct {
+	ProblemID  uuid.UUID
+	Language   int32
+	SourceCode string
+	Files      []byte
+	UpdatedAt  time.Time
+	CreatedAt  time.Time
+}
+
+type ProblemHarness struct {
+	ProblemID   uuid.UUID
+	Language    int32
+	Template    string
+	StarterCode string
 	UpdatedAt   time.Time
+	CreatedAt   time.Time
+}
+
+type ProblemInteractor struct {
+	ProblemID  uuid.UUID
+	Language   int32
+	SourceCode string
+	Files      []byte
+	UpdatedAt  time.Time
+	CreatedAt  time.Time
 }
 
 type ProblemsDescription struct {
@@ -56,6 +113,10 @@ type Submission struct {
 	Status     string
 	UpdatedAt  time.Time
 	CreatedAt  time.Time
+	FailedTest sql.NullInt32
+	Score      sql.NullFloat64
+	Files      []byte
+	EntryPoint string
 }
 
 type SubmissionResult struct {
@@ -71,6 +132,14 @@ type SubmissionResult struct {
 	JudgeResponse  []byte
 	UpdatedAt      time.Time
 	CreatedAt      time.Time
+	QueryLog       string
+	TestNumber     int32
+	CompileOutput  string
+	Stderr         string
+	Message        string
+	ExitCode       int32
+	ExitSignal     int32
+	Visibility     string
 }
 
 type TestCase struct {
@@ -79,6 +148,16 @@ type TestCase struct {
 	Number         int32
 	Stdin          string
 	ExpectedOutput string
+	GroupNumber    int32
+	Visibility     string
+}
+
+type TestGroup struct {
+	ID           uuid.UUID
+	ProblemID    uuid.UUID
+	Number       int32
+	Points       float64
+	Dependencies []int32
 }
 
 type User struct {
@@ -45,22 +45,40 @@ INSERT INTO problems (
     difficulty,
     tags,
     time_limit,
-    memory_limit
+    memory_limit,
+    judging_mode,
+    scoring_mode,
+    comparison_mode,
+    comparison_epsilon,
+    problem_type,
+    function_signature
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
-) RETURNING id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+) RETURNING id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at, judging_mode, scoring_mode, comparison_mode, comparison_epsilon, problem_type, function_signature
 `
 
 type CreateProblemParams struct {
-	Title       string
-	Difficulty  string
-	Tags        []string
-	TimeLimit   float64
-	MemoryLimit float64
+	Title             string
+	Difficulty        string
+	Tags              []string
+	TimeLimit         float64
+	MemoryLimit       float64
+	JudgingMode       string
+	ScoringMode       string
+	ComparisonMode    string
+	ComparisonEpsilon float64
+	ProblemType       string
+	FunctionSignature string
 }
 
 func (q *Queries) CreateProblem(ctx context.Context, arg CreateProblemParams) (Problem, error) {
@@ -70,6 +88,12 @@ func (q *Queries) CreateProblem(ctx context.Context, arg CreateProblemParams) (P
 		pq.Array(arg.Tags),
 		arg.TimeLimit,
 		arg.MemoryLimit,
+		arg.JudgingMode,
+		arg.ScoringMode,
+		arg.ComparisonMode,
+		arg.ComparisonEpsilon,
+		arg.ProblemType,
+		arg.FunctionSignature,
 	)
 	var i Problem
 	err := row.Scan(
@@ -81,6 +105,12 @@ func (q *Queries) CreateProblem(ctx context.Context, arg CreateProblemParams) (P
 		&i.MemoryLimit,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JudgingMode,
+		&i.ScoringMode,
+		&i.ComparisonMode,
+		&i.ComparisonEpsilon,
+		&i.ProblemType,
+		&i.FunctionSignature,
 	)
 	return i, err
 }
@@ -93,31 +123,45 @@ WITH numbered_arrays AS (
             COALESCE((SELECT MAX(number) FROM test_cases WHERE problem_id = $1), 0) + array_length($2::TEXT[], 1)
         ) as num,
         unnest($2::TEXT[]) as in_data,
-        unnest($3::TEXT[]) as out_data
+        unnest($3::TEXT[]) as out_data,
+        unnest($4::INTEGER[]) as group_data,
+        unnest($5::VARCHAR[]) as visibility_data
 )
 INSERT INTO test_cases (
     problem_id,
     number,
     stdin,
-    expected_output
+    expected_output,
+    group_number,
+    visibility
 ) 
 SELECT 
     $1,
     num,
     in_data,
-    out_data
+    out_data,
+    group_data,
+    visibility_data
 FROM numbered_arrays
-RETURNING id, problem_id, number, stdin, expected_output
+RETURNING id, problem_id, number, stdin, expected_output, group_number, visibility
 `
 
 type CreateTestCasesParams struct {
 	ProblemID       uuid.UUID
 	Stdins          []string
 	ExpectedOutputs []string
+	GroupNumbers    []int32
+	Visibilities    []string
 }
 
 func (q *Queries) CreateTestCases(ctx context.Context, arg CreateTestCasesParams) ([]TestCase, error) {
-	rows, err := q.db.QueryContext(ctx, createTestCases, arg.ProblemID, pq.Array(arg.Stdins), pq.Array(arg.ExpectedOutputs))
+	rows, err := q.db.QueryContext(ctx, createTestCases,
+		arg.ProblemID,
+		pq.Array(arg.Stdins),
+		pq.Array(arg.ExpectedOutputs),
+		pq.Array(arg.GroupNumbers),
+		pq.Array(arg.Visibilities),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +175,8 @@ func (q *Queries) CreateTestCases(ctx context.Context, arg CreateTestCasesParams
 			&i.Number,
 			&i.Stdin,
 			&i.ExpectedOutput,
+			&i.GroupNumber,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -146,7 +192,7 @@ func (q *Queries) CreateTestCases(ctx context.Context, arg CreateTestCasesParams
 }
 
 const deleteProblem = `-- name: DeleteProblem :one
-DELETE FROM problems WHERE id = $1 RETURNING id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at
+DELETE FROM problems WHERE id = $1 RETURNING id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at, judging_mode, scoring_mode, comparison_mode, comparison_epsilon, problem_type, function_signature
 `
 
 func (q *Queries) DeleteProblem(ctx context.Context, id uuid.UUID) (Problem, error) {
@@ -161,6 +207,12 @@ func (q *Queries) DeleteProblem(ctx context.Context, id uuid.UUID) (Problem, err
 		&i.MemoryLimit,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JudgingMode,
+		&i.ScoringMode,
+		&i.ComparisonMode,
+		&i.ComparisonEpsilon,
+		&i.ProblemType,
+		&i.FunctionSignature,
 	)
 	return i, err
 }
@@ -182,7 +234,7 @@ func (q *Queries) DeleteProblemDescription(ctx context.Context, problemID uuid.U
 }
 
 const deleteTestCases = `-- name: DeleteTestCases :many
-DELETE FROM test_cases WHERE problem_id = $1 RETURNING id, problem_id, number, stdin, expected_output
+DELETE FROM test_cases WHERE problem_id = $1 RETURNING id, problem_id, number, stdin, expected_output, group_number, visibility
 `
 
 func (q *Queries) DeleteTestCases(ctx context.Context, problemID uuid.UUID) ([]TestCase, error) {
@@ -200,6 +252,8 @@ func (q *Queries) DeleteTestCases(ctx context.Context, problemID uuid.UUID) ([]T
 			&i.Number,
 			&i.Stdin,
 			&i.ExpectedOutput,
+			&i.GroupNumber,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -215,7 +269,7 @@ func (q *Queries) DeleteTestCases(ctx context.Context, problemID uuid.UUID) ([]T
 }
 
 const getProblemByID = `-- name: GetProblemByID :one
-SELECT id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at FROM problems WHERE id = $1
+SELECT id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at, judging_mode, scoring_mode, comparison_mode, comparison_epsilon, problem_type, function_signature FROM problems WHERE id = $1
 `
 
 func (q *Queries) GetProblemByID(ctx context.Context, id uuid.UUID) (Problem, error) {
@@ -230,6 +284,12 @@ func (q *Queries) GetProblemByID(ctx context.Context, id uuid.UUID) (Problem, er
 		&i.MemoryLimit,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JudgingMode,
+		&i.ScoringMode,
+		&i.ComparisonMode,
+		&i.ComparisonEpsilon,
+		&i.ProblemType,
+		&i.FunctionSignature,
 	)
 	return i, err
 }
@@ -251,7 +311,7 @@ func (q *Queries) GetProblemDescription(ctx context.Context, problemID uuid.UUID
 }
 
 const getProblems = `-- name: GetProblems :many
-SELECT id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at FROM problems OFFSET $1 LIMIT $2
+SELECT id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at, judging_mode, scoring_mode, comparison_mode, comparison_epsilon, problem_type, function_signature FROM problems OFFSET $1 LIMIT $2
 `
 
 type GetProblemsParams struct {
@@ -277,6 +337,12 @@ func (q *Queries) GetProblems(ctx context.Context, arg GetProblemsParams) ([]Pro
 			&i.MemoryLimit,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.JudgingMode,
+			&i.ScoringMode,
+			&i.ComparisonMode,
+			&i.ComparisonEpsilon,
+			&i.ProblemType,
+			&i.FunctionSignature,
 		); err != nil {
 			return nil, err
 		}
@@ -292,7 +358,7 @@ func (q *Queries) GetProblems(ctx context.Context, arg GetProblemsParams) ([]Pro
 }
 
 const getTestCases = `-- name: GetTestCases :many
-SELECT id, problem_id, number, stdin, expected_output FROM test_cases WHERE problem_id = $1 ORDER BY number
+SELECT id, problem_id, number, stdin, expected_output, group_number, visibility FROM test_cases WHERE problem_id = $1 ORDER BY number
 `
 
 func (q *Queries) GetTestCases(ctx context.Context, problemID uuid.UUID) ([]TestCase, error) {
@@ -310,6 +376,8 @@ func (q *Queries) GetTestCases(ctx context.Context, problemID uuid.UUID) ([]Test
 			&i.Number,
 			&i.Stdin,
 			&i.ExpectedOutput,
+			&i.GroupNumber,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -331,17 +399,29 @@ UPDATE problems SET
     tags = $4,
     time_limit = $5,
     memory_limit = $6,
+    judging_mode = $7,
+    scoring_mode = $8,
+    comparison_mode = $9,
+    comparison_epsilon = $10,
+    problem_type = $11,
+    function_signature = $12,
     updated_at = now()
-WHERE id = $1 RETURNING id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at
+WHERE id = $1 RETURNING id, title, difficulty, tags, time_limit, memory_limit, created_at, updated_at, judging_mode, scoring_mode, comparison_mode, comparison_epsilon, problem_type, function_signature
 `
 
 type UpdateProblemParams struct {
-	ID          uuid.UUID
-	Title       string
-	Difficulty  string
-	Tags        []string
-	TimeLimit   float64
-	MemoryLimit float64
+	ID                uuid.UUID
+	Title             string
+	Difficulty        string
+	Tags              []string
+	TimeLimit         float64
+	MemoryLimit       float64
+	JudgingMode       string
+	ScoringMode       string
+	ComparisonMode    string
+	ComparisonEpsilon float64
+	ProblemType       string
+	FunctionSignature string
 }
 
 func (q *Queries) UpdateProblem(ctx context.Context, arg UpdateProblemParams) (Problem, error) {
@@ -352,6 +432,12 @@ func (q *Queries) UpdateProblem(ctx context.Context, arg UpdateProblemParams) (P
 		pq.Array(arg.Tags),
 		arg.TimeLimit,
 		arg.MemoryLimit,
+		arg.JudgingMode,
+		arg.ScoringMode,
+		arg.ComparisonMode,
+		arg.ComparisonEpsilon,
+		arg.ProblemType,
+		arg.FunctionSignature,
 	)
 	var i Problem
 	err := row.Scan(
@@ -363,6 +449,12 @@ func (q *Queries) UpdateProblem(ctx context.Context, arg UpdateProblemParams) (P
 		&i.MemoryLimit,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.JudgingMode,
+		&i.ScoringMode,
+		&i.ComparisonMode,
+		&i.ComparisonEpsilon,
+		&i.ProblemType,
+		&i.FunctionSignature,
 	)
 	return i, err
 }
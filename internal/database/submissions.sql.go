@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -18,14 +19,18 @@ INSERT INTO submissions (
     user_id,
     language,
     source_code,
-    status
+    status,
+    files,
+    entry_point
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
-) RETURNING id, problem_id, user_id, language, source_code, status, updated_at, created_at
+    $5,
+    $6,
+    $7
+) RETURNING id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point
 `
 
 type CreateSubmissionParams struct {
@@ -34,6 +39,8 @@ type CreateSubmissionParams struct {
 	Language   int32
 	SourceCode string
 	Status     string
+	Files      []byte
+	EntryPoint string
 }
 
 func (q *Queries) CreateSubmission(ctx context.Context, arg CreateSubmissionParams) (Submission, error) {
@@ -43,6 +50,8 @@ func (q *Queries) CreateSubmission(ctx context.Context, arg CreateSubmissionPara
 		arg.Language,
 		arg.SourceCode,
 		arg.Status,
+		arg.Files,
+		arg.EntryPoint,
 	)
 	var i Submission
 	err := row.Scan(
@@ -54,6 +63,10 @@ func (q *Queries) CreateSubmission(ctx context.Context, arg CreateSubmissionPara
 		&i.Status,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.FailedTest,
+		&i.Score,
+		&i.Files,
+		&i.EntryPoint,
 	)
 	return i, err
 }
@@ -81,7 +94,7 @@ INSERT INTO submission_results (
     $8,
     $9,
     $10
-) RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at
+) RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at, query_log, test_number, compile_output, stderr, message, exit_code, exit_signal, visibility
 `
 
 type CreateSubmissionResultParams struct {
@@ -124,6 +137,14 @@ func (q *Queries) CreateSubmissionResult(ctx context.Context, arg CreateSubmissi
 		&i.JudgeResponse,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.QueryLog,
+		&i.TestNumber,
+		&i.CompileOutput,
+		&i.Stderr,
+		&i.Message,
+		&i.ExitCode,
+		&i.ExitSignal,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -139,7 +160,15 @@ INSERT INTO submission_results (
     status_id,
     time_used,
     memory_used,
-    judge_response
+    judge_response,
+    query_log,
+    test_number,
+    compile_output,
+    stderr,
+    message,
+    exit_code,
+    exit_signal,
+    visibility
 ) 
 VALUES (
     gen_random_uuid(),
@@ -151,9 +180,17 @@ VALUES (
     unnest($6::integer[]),
     unnest($7::text[]),
     unnest($8::float8[]),
-    unnest($9::bytea[])
+    unnest($9::bytea[]),
+    unnest($10::text[]),
+    unnest($11::integer[]),
+    unnest($12::text[]),
+    unnest($13::text[]),
+    unnest($14::text[]),
+    unnest($15::integer[]),
+    unnest($16::integer[]),
+    unnest($17::VARCHAR[])
 )
-RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at
+RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at, query_log, test_number, compile_output, stderr, message, exit_code, exit_signal, visibility
 `
 
 type CreateSubmissionResultsParams struct {
@@ -166,6 +203,14 @@ type CreateSubmissionResultsParams struct {
 	Times           []string
 	Memories        []float64
 	Responses       [][]byte
+	QueryLogs       []string
+	TestNumbers     []int32
+	CompileOutputs  []string
+	Stderrs         []string
+	Messages        []string
+	ExitCodes       []int32
+	ExitSignals     []int32
+	Visibilities    []string
 }
 
 func (q *Queries) CreateSubmissionResults(ctx context.Context, arg CreateSubmissionResultsParams) ([]SubmissionResult, error) {
@@ -179,6 +224,14 @@ func (q *Queries) CreateSubmissionResults(ctx context.Context, arg CreateSubmiss
 		pq.Array(arg.Times),
 		pq.Array(arg.Memories),
 		pq.Array(arg.Responses),
+		pq.Array(arg.QueryLogs),
+		pq.Array(arg.TestNumbers),
+		pq.Array(arg.CompileOutputs),
+		pq.Array(arg.Stderrs),
+		pq.Array(arg.Messages),
+		pq.Array(arg.ExitCodes),
+		pq.Array(arg.ExitSignals),
+		pq.Array(arg.Visibilities),
 	)
 	if err != nil {
 		return nil, err
@@ -200,6 +253,14 @@ func (q *Queries) CreateSubmissionResults(ctx context.Context, arg CreateSubmiss
 			&i.JudgeResponse,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.QueryLog,
+			&i.TestNumber,
+			&i.CompileOutput,
+			&i.Stderr,
+			&i.Message,
+			&i.ExitCode,
+			&i.ExitSignal,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -215,7 +276,7 @@ func (q *Queries) CreateSubmissionResults(ctx context.Context, arg CreateSubmiss
 }
 
 const getSubmissionByID = `-- name: GetSubmissionByID :one
-SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at FROM submissions WHERE id = $1
+SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point FROM submissions WHERE id = $1
 `
 
 func (q *Queries) GetSubmissionByID(ctx context.Context, id uuid.UUID) (Submission, error) {
@@ -230,12 +291,16 @@ func (q *Queries) GetSubmissionByID(ctx context.Context, id uuid.UUID) (Submissi
 		&i.Status,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.FailedTest,
+		&i.Score,
+		&i.Files,
+		&i.EntryPoint,
 	)
 	return i, err
 }
 
 const getSubmissionResultByID = `-- name: GetSubmissionResultByID :one
-SELECT id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at FROM submission_results WHERE id = $1
+SELECT id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at, query_log, test_number, compile_output, stderr, message, exit_code, exit_signal, visibility FROM submission_results WHERE id = $1
 `
 
 func (q *Queries) GetSubmissionResultByID(ctx context.Context, id uuid.UUID) (SubmissionResult, error) {
@@ -254,12 +319,20 @@ func (q *Queries) GetSubmissionResultByID(ctx context.Context, id uuid.UUID) (Su
 		&i.JudgeResponse,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.QueryLog,
+		&i.TestNumber,
+		&i.CompileOutput,
+		&i.Stderr,
+		&i.Message,
+		&i.ExitCode,
+		&i.ExitSignal,
+		&i.Visibility,
 	)
 	return i, err
 }
 
 const getSubmissionResultsBySubmissionID = `-- name: GetSubmissionResultsBySubmissionID :many
-SELECT id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at FROM submission_results WHERE submission_id = $1 OFFSET $2 LIMIT $3
+SELECT id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at, query_log, test_number, compile_output, stderr, message, exit_code, exit_signal, visibility FROM submission_results WHERE submission_id = $1 ORDER BY test_number OFFSET $2 LIMIT $3
 `
 
 type GetSubmissionResultsBySubmissionIDParams struct {
@@ -290,6 +363,14 @@ func (q *Queries) GetSubmissionResultsBySubmissionID(ctx context.Context, arg Ge
 			&i.JudgeResponse,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.QueryLog,
+			&i.TestNumber,
+			&i.CompileOutput,
+			&i.Stderr,
+			&i.Message,
+			&i.ExitCode,
+			&i.ExitSignal,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -305,7 +386,7 @@ func (q *Queries) GetSubmissionResultsBySubmissionID(ctx context.Context, arg Ge
 }
 
 const getSubmissions = `-- name: GetSubmissions :many
-SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at FROM submissions ORDER BY created_at DESC OFFSET $1 LIMIT $2
+SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point FROM submissions ORDER BY created_at DESC OFFSET $1 LIMIT $2
 `
 
 type GetSubmissionsParams struct {
@@ -331,6 +412,10 @@ func (q *Queries) GetSubmissions(ctx context.Context, arg GetSubmissionsParams)
 			&i.Status,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.FailedTest,
+			&i.Score,
+			&i.Files,
+			&i.EntryPoint,
 		); err != nil {
 			return nil, err
 		}
@@ -346,7 +431,7 @@ func (q *Queries) GetSubmissions(ctx context.Context, arg GetSubmissionsParams)
 }
 
 const getSubmissionsByProblemID = `-- name: GetSubmissionsByProblemID :many
-SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at FROM submissions WHERE problem_id = $1 ORDER BY created_at DESC OFFSET $2 LIMIT $3
+SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point FROM submissions WHERE problem_id = $1 ORDER BY created_at DESC OFFSET $2 LIMIT $3
 `
 
 type GetSubmissionsByProblemIDParams struct {
@@ -373,6 +458,10 @@ func (q *Queries) GetSubmissionsByProblemID(ctx context.Context, arg GetSubmissi
 			&i.Status,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.FailedTest,
+			&i.Score,
+			&i.Files,
+			&i.EntryPoint,
 		); err != nil {
 			return nil, err
 		}
@@ -388,7 +477,7 @@ func (q *Queries) GetSubmissionsByProblemID(ctx context.Context, arg GetSubmissi
 }
 
 const getSubmissionsByUserID = `-- name: GetSubmissionsByUserID :many
-SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at FROM submissions WHERE user_id = $1 ORDER BY created_at DESC OFFSET $2 LIMIT $3
+SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point FROM submissions WHERE user_id = $1 ORDER BY created_at DESC OFFSET $2 LIMIT $3
 `
 
 type GetSubmissionsByUserIDParams struct {
@@ -415,6 +504,10 @@ func (q *Queries) GetSubmissionsByUserID(ctx context.Context, arg GetSubmissions
 			&i.Status,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.FailedTest,
+			&i.Score,
+			&i.Files,
+			&i.EntryPoint,
 		); err != nil {
 			return nil, err
 		}
@@ -430,7 +523,7 @@ func (q *Queries) GetSubmissionsByUserID(ctx context.Context, arg GetSubmissions
 }
 
 const getUserSubmissionsForProblem = `-- name: GetUserSubmissionsForProblem :many
-SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at FROM submissions 
+SELECT id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point FROM submissions 
 WHERE user_id = $1 AND problem_id = $2 
 ORDER BY created_at DESC
 OFFSET $3 LIMIT $4
@@ -466,6 +559,10 @@ func (q *Queries) GetUserSubmissionsForProblem(ctx context.Context, arg GetUserS
 			&i.Status,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.FailedTest,
+			&i.Score,
+			&i.Files,
+			&i.EntryPoint,
 		); err != nil {
 			return nil, err
 		}
@@ -490,7 +587,7 @@ UPDATE submission_results SET
     memory_used = $7,
     judge_response = $8,
     updated_at = now()
-WHERE id = $1 RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at
+WHERE id = $1 RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at, query_log, test_number, compile_output, stderr, message, exit_code, exit_signal, visibility
 `
 
 type UpdateSubmissionResultParams struct {
@@ -529,6 +626,14 @@ func (q *Queries) UpdateSubmissionResult(ctx context.Context, arg UpdateSubmissi
 		&i.JudgeResponse,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.QueryLog,
+		&i.TestNumber,
+		&i.CompileOutput,
+		&i.Stderr,
+		&i.Message,
+		&i.ExitCode,
+		&i.ExitSignal,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -537,7 +642,7 @@ const updateSubmissionResultStatus = `-- name: UpdateSubmissionResultStatus :one
 UPDATE submission_results SET
     status_id = $2,
     updated_at = now()
-WHERE id = $1 RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at
+WHERE id = $1 RETURNING id, submission_id, judge_token, stdin, stdout, expected_output, status_id, time_used, memory_used, judge_response, updated_at, created_at, query_log, test_number, compile_output, stderr, message, exit_code, exit_signal, visibility
 `
 
 type UpdateSubmissionResultStatusParams struct {
@@ -561,6 +666,14 @@ func (q *Queries) UpdateSubmissionResultStatus(ctx context.Context, arg UpdateSu
 		&i.JudgeResponse,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.QueryLog,
+		&i.TestNumber,
+		&i.CompileOutput,
+		&i.Stderr,
+		&i.Message,
+		&i.ExitCode,
+		&i.ExitSignal,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -569,7 +682,7 @@ const updateSubmissionStatus = `-- name: UpdateSubmissionStatus :one
 UPDATE submissions SET
     status = $2,
     updated_at = now()
-WHERE id = $1 RETURNING id, problem_id, user_id, language, source_code, status, updated_at, created_at
+WHERE id = $1 RETURNING id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point
 `
 
 type UpdateSubmissionStatusParams struct {
@@ -589,6 +702,51 @@ func (q *Queries) UpdateSubmissionStatus(ctx context.Context, arg UpdateSubmissi
 		&i.Status,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.FailedTest,
+		&i.Score,
+		&i.Files,
+		&i.EntryPoint,
+	)
+	return i, err
+}
+
+const updateSubmissionVerdict = `-- name: UpdateSubmissionVerdict :one
+UPDATE submissions SET
+    status = $2,
+    failed_test = $3,
+    score = $4,
+    updated_at = now()
+WHERE id = $1 RETURNING id, problem_id, user_id, language, source_code, status, updated_at, created_at, failed_test, score, files, entry_point
+`
+
+type UpdateSubmissionVerdictParams struct {
+	ID         uuid.UUID
+	Status     string
+	FailedTest sql.NullInt32
+	Score      sql.NullFloat64
+}
+
+func (q *Queries) UpdateSubmissionVerdict(ctx context.Context, arg UpdateSubmissionVerdictParams) (Submission, error) {
+	row := q.db.QueryRowContext(ctx, updateSubmissionVerdict,
+		arg.ID,
+		arg.Status,
+		arg.FailedTest,
+		arg.Score,
+	)
+	var i Submission
+	err := row.Scan(
+		&i.ID,
+		&i.ProblemID,
+		&i.UserID,
+		&i.Language,
+		&i.SourceCode,
+		&i.Status,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.FailedTest,
+		&i.Score,
+		&i.Files,
+		&i.EntryPoint,
 	)
 	return i, err
 }
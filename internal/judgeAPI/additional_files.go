@@ -0,0 +1,109 @@
+package judgeAPI
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// judge zero takes additional_files as a base64 zip, it is extracted next to
+// the source code before compiling and running it
+
+// limits of what UnzipFiles extracts, a small upload can hide a zip bomb
+const (
+	MAX_ZIP_FILES          = 1000
+	MAX_UNZIPPED_FILE_SIZE = 32 << 20
+	MAX_UNZIPPED_SIZE      = 64 << 20
+)
+
+func ZipFiles(files map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("error adding %s to zip: %w", name, err)
+		}
+		_, err = fw.Write(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("error writing %s to zip: %w", name, err)
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error closing zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func UnzipFiles(data []byte) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if len(data) == 0 {
+		return files, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading zip: %w", err)
+	}
+
+	if len(zr.File) > MAX_ZIP_FILES {
+		return nil, fmt.Errorf("zip has %d files, at most %d are allowed", len(zr.File), MAX_ZIP_FILES)
+	}
+
+	// the sizes in the zip headers can lie, what is actually read is counted
+	total := 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error opening %s in zip: %w", f.Name, err)
+		}
+		limit := min(MAX_UNZIPPED_FILE_SIZE, MAX_UNZIPPED_SIZE-total)
+		content, err := io.ReadAll(io.LimitReader(rc, int64(limit)+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s in zip: %w", f.Name, err)
+		}
+		if len(content) > MAX_UNZIPPED_FILE_SIZE {
+			return nil, fmt.Errorf("%s in zip is bigger than %d bytes", f.Name, MAX_UNZIPPED_FILE_SIZE)
+		}
+		if len(content) > limit {
+			return nil, fmt.Errorf("zip is bigger than %d bytes when extracted", MAX_UNZIPPED_SIZE)
+		}
+
+		total += len(content)
+		files[f.Name] = content
+	}
+
+	return files, nil
+}
+
+func EncodeAdditionalFiles(files map[string][]byte) (string, error) {
+	data, err := ZipFiles(files)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func DecodeAdditionalFiles(encoded string) (map[string][]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("additional files are not base64: %w", err)
+	}
+	return UnzipFiles(data)
+}
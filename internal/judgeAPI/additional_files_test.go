@@ -0,0 +1,44 @@
+package judgeAPI
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestUnzipFilesLimits(t *testing.T) {
+	zipped := func(files map[string][]byte) []byte {
+		data, err := ZipFiles(files)
+		if err != nil {
+			t.Fatalf("error zipping files: %v", err)
+		}
+		return data
+	}
+
+	files, err := UnzipFiles(zipped(map[string][]byte{"main.py": []byte("print(1)"), "lib/a.py": []byte("")}))
+	if err != nil || string(files["main.py"]) != "print(1)" || len(files) != 2 {
+		t.Fatalf("got files %v and error %v", files, err)
+	}
+
+	// zeros compress to almost nothing, these are all small zips
+	big := bytes.Repeat([]byte{0}, MAX_UNZIPPED_FILE_SIZE+1)
+	_, err = UnzipFiles(zipped(map[string][]byte{"big": big}))
+	if err == nil {
+		t.Fatalf("a file over the size limit was extracted")
+	}
+
+	half := bytes.Repeat([]byte{0}, MAX_UNZIPPED_SIZE/2)
+	_, err = UnzipFiles(zipped(map[string][]byte{"a": half, "b": half, "c": []byte("1")}))
+	if err == nil {
+		t.Fatalf("files over the total size limit were extracted")
+	}
+
+	many := make(map[string][]byte, MAX_ZIP_FILES+1)
+	for i := range MAX_ZIP_FILES + 1 {
+		many[fmt.Sprintf("%d.out", i)] = []byte("1")
+	}
+	_, err = UnzipFiles(zipped(many))
+	if err == nil {
+		t.Fatalf("a zip over the file count limit was extracted")
+	}
+}
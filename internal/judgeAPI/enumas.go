@@ -38,46 +38,53 @@ const (
 	RUBY          JudgeLanguage = 72
 	RUST          JudgeLanguage = 73
 	TYPESCRIPT    JudgeLanguage = 74
+	// judge zero runs the compile and run scripts from additional_files
+	MULTI_FILE_PROGRAM JudgeLanguage = 89
 )
 
 var JudgeLanguageDescription = map[JudgeLanguage]string{
-	PYTHON_312:    "Python (3.12)",
-	PYTHON_311:    "Python (3.11)",
-	C_CLANG18:     "C (Clang 18)",
-	ASSEMBLY_NASM: "Assembly (NASM 2.14.02)",
-	BASH:          "Bash (5.0.0)",
-	BASIC_FBC:     "Basic (FBC 1.07.1)",
-	C_GCC_7:       "C (GCC 7.4.0)",
-	CPP_GCC_7:     "C++ (GCC 7.4.0)",
-	C_GCC_8:       "C (GCC 8.3.0)",
-	CPP_GCC_8:     "C++ (GCC 8.3.0)",
-	C_GCC_9:       "C (GCC 9.2.0)",
-	CPP_GCC_9:     "C++ (GCC 9.2.0)",
-	CSHARP_MONO:   "C# (Mono 6.6.0.161)",
-	COMMON_LISP:   "Common Lisp (SBCL 2.0.0)",
-	D_DMD:         "D (DMD 2.089.1)",
-	ELIXIR:        "Elixir (1.9.4)",
-	ERLANG:        "Erlang (OTP 22.2)",
-	EXECUTABLE:    "Executable",
-	FORTRAN:       "Fortran (GFortran 9.2.0)",
-	GO:            "Go (1.13.5)",
-	HASKELL:       "Haskell (GHC 8.8.1)",
-	JAVA:          "Java (OpenJDK 13.0.1)",
-	JAVASCRIPT:    "JavaScript (Node.js 12.14.0)",
-	LUA:           "Lua (5.3.5)",
-	OCAML:         "OCaml (4.09.0)",
-	OCTAVE:        "Octave (5.1.0)",
-	PASCAL:        "Pascal (FPC 3.0.4)",
-	PHP:           "PHP (7.4.1)",
-	PLAIN_TEXT:    "Plain Text",
-	PROLOG:        "Prolog (GNU Prolog 1.4.5)",
-	PYTHON_2:      "Python (2.7.17)",
-	PYTHON_3:      "Python (3.8.1)",
-	RUBY:          "Ruby (2.7.0)",
-	RUST:          "Rust (1.40.0)",
-	TYPESCRIPT:    "TypeScript (3.7.4)",
+	PYTHON_312:         "Python (3.12)",
+	PYTHON_311:         "Python (3.11)",
+	C_CLANG18:          "C (Clang 18)",
+	ASSEMBLY_NASM:      "Assembly (NASM 2.14.02)",
+	BASH:               "Bash (5.0.0)",
+	BASIC_FBC:          "Basic (FBC 1.07.1)",
+	C_GCC_7:            "C (GCC 7.4.0)",
+	CPP_GCC_7:          "C++ (GCC 7.4.0)",
+	C_GCC_8:            "C (GCC 8.3.0)",
+	CPP_GCC_8:          "C++ (GCC 8.3.0)",
+	C_GCC_9:            "C (GCC 9.2.0)",
+	CPP_GCC_9:          "C++ (GCC 9.2.0)",
+	CSHARP_MONO:        "C# (Mono 6.6.0.161)",
+	COMMON_LISP:        "Common Lisp (SBCL 2.0.0)",
+	D_DMD:              "D (DMD 2.089.1)",
+	ELIXIR:             "Elixir (1.9.4)",
+	ERLANG:             "Erlang (OTP 22.2)",
+	EXECUTABLE:         "Executable",
+	FORTRAN:            "Fortran (GFortran 9.2.0)",
+	GO:                 "Go (1.13.5)",
+	HASKELL:            "Haskell (GHC 8.8.1)",
+	JAVA:               "Java (OpenJDK 13.0.1)",
+	JAVASCRIPT:         "JavaScript (Node.js 12.14.0)",
+	LUA:                "Lua (5.3.5)",
+	OCAML:              "OCaml (4.09.0)",
+	OCTAVE:             "Octave (5.1.0)",
+	PASCAL:             "Pascal (FPC 3.0.4)",
+	PHP:                "PHP (7.4.1)",
+	PLAIN_TEXT:         "Plain Text",
+	PROLOG:             "Prolog (GNU Prolog 1.4.5)",
+	PYTHON_2:           "Python (2.7.17)",
+	PYTHON_3:           "Python (3.8.1)",
+	RUBY:               "Ruby (2.7.0)",
+	RUST:               "Rust (1.40.0)",
+	TYPESCRIPT:         "TypeScript (3.7.4)",
+	MULTI_FILE_PROGRAM: "Multi-file program",
 }
 
+// judge zero has no memory limit status, a run killed for going over the
+// limit comes back as a runtime error with this message
+const MEMORY_LIMIT_EXCEEDED_MESSAGE = "Exited with error status 137"
+
 type JudgeSubmissionStatusID int
 
 const (
@@ -0,0 +1,152 @@
+package judgeAPI
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exit codes of an interactive run, the interactor ones are testlib's
+const (
+	INTERACTOR_OK                 = 0
+	INTERACTOR_WRONG_ANSWER       = 1
+	INTERACTOR_PRESENTATION_ERROR = 2
+	INTERACTOR_FAILED             = 3
+	// the interactor was happy but the solution did not exit cleanly
+	INTERACTIVE_SOLUTION_FAILED = 4
+)
+
+// prefixes of the query log lines, what the solution asked and what the
+// interactor answered
+const (
+	QUERY_LOG_SOLUTION   = "> "
+	QUERY_LOG_INTERACTOR = "< "
+)
+
+const interactiveCompileScript = `#!/bin/bash
+export PATH="%[1]s"
+# a broken interactor is not the solution fault, run reports it
+(cd interactor && %[2]s) > interactor_compile.txt 2>&1 || exit 0
+touch interactor_ready
+cd solution && %[3]s
+`
+
+// nothing the solution should not see is left on disk once it starts. the
+// test goes to the interactor through a fifo, the solution only starts once
+// the interactor opened it and then the fifo and the interactor dir are
+// removed. the logs are deleted files only this script still has open
+const interactiveRunScript = `#!/bin/bash
+export PATH="%[1]s"
+if [ ! -f interactor_ready ]; then
+	echo "interactor did not compile" >&2
+	cat interactor_compile.txt >&2
+	exit %[6]d
+fi
+rm -f interactor_compile.txt
+
+exec 3<&0
+exec 4>> query_log.txt 5< query_log.txt 6>> interactor_stderr.txt 7< interactor_stderr.txt
+rm query_log.txt interactor_stderr.txt
+
+relay() {
+	while IFS= read -r line || [ -n "$line" ]; do
+		printf '%%s%%s\n' "$1" "$line" >&4
+		printf '%%s\n' "$line"
+	done
+}
+
+mkfifo test_input to_solution to_interactor
+# a writer so the interactor can open its stdin before the solution runs
+exec 8<> to_interactor
+
+(
+	(cd interactor && %[2]s ../test_input /dev/null) < to_interactor 2>&6 8<&- | relay "%[5]s" > to_solution 8<&-
+	exit "${PIPESTATUS[0]}"
+) 3<&- &
+interactor_pid=$!
+
+(
+	exec 9> test_input
+	touch test_opened
+	cat <&3 >&9
+) 8<&- > /dev/null &
+input_pid=$!
+
+while [ ! -e test_opened ] && kill -0 $interactor_pid 2> /dev/null; do
+	sleep 0.01
+done
+rm -rf test_input test_opened interactor
+
+(
+	(cd solution && %[3]s) < to_solution 2> /dev/null 4>&- 5<&- 6>&- 7<&- | relay "%[4]s" > to_interactor
+	exit "${PIPESTATUS[0]}"
+) 3<&- 8<&- &
+solution_pid=$!
+exec 8<&-
+
+wait $interactor_pid
+interactor_status=$?
+wait $solution_pid
+solution_status=$?
+# the interactor may not have read the whole test
+kill $input_pid 2> /dev/null
+
+cat <&5
+cat <&7 >&2
+
+case $interactor_status in
+	%[7]d) ;;
+	%[8]d|%[9]d) exit $interactor_status ;;
+	*) exit %[6]d ;;
+esac
+
+if [ $solution_status -ne 0 ]; then
+	echo "solution exited with status $solution_status" >&2
+	exit %[10]d
+fi
+`
+
+// NewInteractiveSubmission runs solution against a testlib style interactor
+// as a multi-file program. the interactor gets the test stdin as its input
+// file, the two talk through fifos and the exchange comes back as the
+// stdout of the run, one line per message. the verdict is in the exit code,
+// see the INTERACTOR_ codes. both programs share the time limit
+func NewInteractiveSubmission(solution Program, interactor Program) (*Submission, error) {
+	files := map[string][]byte{}
+
+	compileInteractor, runInteractor, err := interactor.addTo(files, "interactor")
+	if err != nil {
+		return nil, fmt.Errorf("error adding interactor: %w", err)
+	}
+	compileSolution, runSolution, err := solution.addTo(files, "solution")
+	if err != nil {
+		return nil, fmt.Errorf("error adding solution: %w", err)
+	}
+
+	files["compile"] = []byte(fmt.Sprintf(interactiveCompileScript, SCRIPT_PATH, compileInteractor, compileSolution))
+	files["run"] = []byte(fmt.Sprintf(interactiveRunScript,
+		SCRIPT_PATH, runInteractor, runSolution,
+		QUERY_LOG_SOLUTION, QUERY_LOG_INTERACTOR,
+		INTERACTOR_FAILED, INTERACTOR_OK, INTERACTOR_WRONG_ANSWER, INTERACTOR_PRESENTATION_ERROR,
+		INTERACTIVE_SOLUTION_FAILED,
+	))
+
+	encoded, err := EncodeAdditionalFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding interactive program: %w", err)
+	}
+
+	submission := NewSubmission("", MULTI_FILE_PROGRAM)
+	submission.SetAdditionalFiles(encoded)
+	return submission, nil
+}
+
+// SolutionOutput is what the solution printed in an interactive query log
+func SolutionOutput(queryLog string) string {
+	output := strings.Builder{}
+	for _, line := range strings.SplitAfter(queryLog, "\n") {
+		if query, ok := strings.CutPrefix(line, QUERY_LOG_SOLUTION); ok {
+			output.WriteString(query)
+		}
+	}
+	return output.String()
+}
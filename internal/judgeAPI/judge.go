@@ -0,0 +1,41 @@
+package judgeAPI
+
+// judge zero takes at most this many submissions or tokens in one batch request
+const MAX_BATCH_SIZE = 20
+
+// RESULT_FIELDS are the submission fields we keep from a result, batch
+// results are requested with only these to keep the responses small
+var RESULT_FIELDS = []string{
+	"token",
+	"stdin",
+	"expected_output",
+	"stdout",
+	"stderr",
+	"compile_output",
+	"message",
+	"exit_code",
+	"exit_signal",
+	"status",
+	"time",
+	"wall_time",
+	"memory",
+}
+
+// Judge is implemented by every backend that can run submissions,
+// the pipeline and the server only talk to the judge through it
+type Judge interface {
+	PostBatchSubmission(bs *SubmissionBatch) ([]string, error)
+	GetBatchSubmissionsResult(tokens []string) ([]Submission, error)
+	GetLanguages() ([]Language, error)
+	CheckHealth() error
+}
+
+type Language struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var (
+	_ Judge = (*JudgeAPI)(nil)
+	_ Judge = (*SelfHostedJudgeAPI)(nil)
+)
@@ -0,0 +1,362 @@
+// Package judge0test is an in process stand in for judge zero, it serves the
+// endpoints judgeAPI.JudgeAPI calls with scriptable verdicts and failures.
+package judge0test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/utils"
+)
+
+const (
+	POST_SUBMISSION_ENDPOINT       = "POST /submissions"
+	POST_BATCH_SUBMISSION_ENDPOINT = "POST /submissions/batch"
+	GET_BATCH_SUBMISSION_ENDPOINT  = "GET /submissions/batch"
+	GET_LANGUAGES_ENDPOINT         = "GET /languages"
+	GET_ABOUT_ENDPOINT             = "GET /about"
+	CALLBACK                       = "PUT callback"
+)
+
+// Verdict is what the fake judge answers for one submission, the submission
+// stays IN_QUEUE for QueuedPolls gets then PROCESSING for ProcessingPolls
+// gets before it reaches Status
+type Verdict struct {
+	Status          judgeAPI.JudgeSubmissionStatusID
+	Stdout          string
+	Stderr          string
+	CompileOutput   string
+	Message         string
+	ExitCode        int
+	Time            string
+	Memory          float64
+	QueuedPolls     int
+	ProcessingPolls int
+	// submissions with a callback url get the final result PUT to it,
+	// unless the callback is dropped to act like a lost request
+	DropCallback bool
+}
+
+type VerdictFunc func(s judgeAPI.Submission) Verdict
+
+// AcceptAll runs every submission as a program that echoes its input
+func AcceptAll(s judgeAPI.Submission) Verdict {
+	return Verdict{
+		Status: judgeAPI.ACCEPTED_STATUS,
+		Stdout: s.Stdin,
+		Time:   "0.01",
+		Memory: 1024,
+	}
+}
+
+type failure struct {
+	statusCode int
+	body       string
+	skip       int // requests handled normally before this one fails
+}
+
+type fakeSubmission struct {
+	submission judgeAPI.Submission
+	verdict    Verdict
+	polls      int
+}
+
+type Server struct {
+	*httptest.Server
+	mu          sync.Mutex
+	verdict     VerdictFunc
+	submissions map[string]*fakeSubmission
+	nextToken   int
+	failures    map[string][]failure
+	requests    map[string]int
+	queries     map[string]url.Values
+}
+
+func NewServer() *Server {
+	s := &Server{
+		verdict:     AcceptAll,
+		submissions: make(map[string]*fakeSubmission),
+		failures:    make(map[string][]failure),
+		requests:    make(map[string]int),
+		queries:     make(map[string]url.Values),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(POST_SUBMISSION_ENDPOINT, s.handle(POST_SUBMISSION_ENDPOINT, s.postSubmission))
+	mux.HandleFunc(POST_BATCH_SUBMISSION_ENDPOINT, s.handle(POST_BATCH_SUBMISSION_ENDPOINT, s.postBatchSubmission))
+	mux.HandleFunc(GET_BATCH_SUBMISSION_ENDPOINT, s.handle(GET_BATCH_SUBMISSION_ENDPOINT, s.getBatchSubmission))
+	mux.HandleFunc(GET_LANGUAGES_ENDPOINT, s.handle(GET_LANGUAGES_ENDPOINT, s.getLanguages))
+	mux.HandleFunc(GET_ABOUT_ENDPOINT, s.handle(GET_ABOUT_ENDPOINT, s.getAbout))
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Judge returns a client talking to this server
+func (s *Server) Judge() *judgeAPI.SelfHostedJudgeAPI {
+	judge, err := judgeAPI.NewSelfHostedJudgeAPI(s.URL, "", "")
+	utils.Assert(err, "error creating judge zero client for fake server")
+	return judge
+}
+
+// SetVerdict changes how the next posted submissions are judged
+func (s *Server) SetVerdict(verdict VerdictFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verdict = verdict
+}
+
+// FailNext makes the next request to endpoint answer with statusCode and body
+// instead of being handled, calls stack up so several failures can be queued
+func (s *Server) FailNext(endpoint string, statusCode int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[endpoint] = append(s.failures[endpoint], failure{statusCode: statusCode, body: body})
+}
+
+// FailAfter lets n requests to endpoint through and makes the one after them
+// answer with statusCode and body
+func (s *Server) FailAfter(endpoint string, n int, statusCode int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[endpoint] = append(s.failures[endpoint], failure{statusCode: statusCode, body: body, skip: n})
+}
+
+// Requests is how many requests endpoint got, failed ones included
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// LastQuery is the url query of the last request to endpoint
+func (s *Server) LastQuery(endpoint string) url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries[endpoint]
+}
+
+// Submissions returns every submission posted so far
+func (s *Server) Submissions() []judgeAPI.Submission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	submissions := make([]judgeAPI.Submission, 0, len(s.submissions))
+	for i := 1; i <= s.nextToken; i++ {
+		if fs, ok := s.submissions[tokenFor(i)]; ok {
+			submissions = append(submissions, fs.submission)
+		}
+	}
+	return submissions
+}
+
+func (s *Server) handle(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[endpoint] += 1
+		s.queries[endpoint] = r.URL.Query()
+
+		var f *failure
+		if queued := s.failures[endpoint]; len(queued) > 0 && queued[0].skip > 0 {
+			queued[0].skip -= 1
+		} else if len(queued) > 0 {
+			f = &queued[0]
+			s.failures[endpoint] = queued[1:]
+		}
+		s.mu.Unlock()
+
+		if f != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.statusCode)
+			w.Write([]byte(f.body))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *Server) postSubmission(w http.ResponseWriter, r *http.Request) {
+	submission := judgeAPI.Submission{}
+	err := json.NewDecoder(r.Body).Decode(&submission)
+	if err != nil {
+		http.Error(w, `{"error": "invalid json"}`, 400)
+		return
+	}
+
+	s.mu.Lock()
+	token := s.store(submission)
+	s.mu.Unlock()
+
+	writeJson(w, 201, map[string]string{"token": token})
+}
+
+func (s *Server) postBatchSubmission(w http.ResponseWriter, r *http.Request) {
+	payload := struct {
+		Submissions []judgeAPI.Submission `json:"submissions"`
+	}{}
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, `{"error": "invalid json"}`, 400)
+		return
+	}
+
+	// like judge zero with its default MAX_SUBMISSION_BATCH_SIZE
+	if len(payload.Submissions) > judgeAPI.MAX_BATCH_SIZE {
+		message := fmt.Sprintf(`{"error": "number of submissions in a batch should be less than or equal to %d"}`, judgeAPI.MAX_BATCH_SIZE)
+		http.Error(w, message, 422)
+		return
+	}
+
+	s.mu.Lock()
+	tokens := make([]map[string]string, len(payload.Submissions))
+	for i, submission := range payload.Submissions {
+		tokens[i] = map[string]string{"token": s.store(submission)}
+	}
+	s.mu.Unlock()
+
+	writeJson(w, 201, tokens)
+}
+
+func (s *Server) getBatchSubmission(w http.ResponseWriter, r *http.Request) {
+	tokens := strings.Split(r.URL.Query().Get("tokens"), ",")
+	if len(tokens) > judgeAPI.MAX_BATCH_SIZE {
+		message := fmt.Sprintf(`{"error": "number of submissions in a batch should be less than or equal to %d"}`, judgeAPI.MAX_BATCH_SIZE)
+		http.Error(w, message, 422)
+		return
+	}
+
+	s.mu.Lock()
+	submissions := make([]*judgeAPI.Submission, len(tokens))
+	for i, token := range tokens {
+		fs, ok := s.submissions[token]
+		if !ok {
+			continue
+		}
+		result := fs.poll()
+		submissions[i] = &result
+	}
+	s.mu.Unlock()
+
+	writeJson(w, 200, map[string]any{"submissions": submissions})
+}
+
+func (s *Server) getLanguages(w http.ResponseWriter, r *http.Request) {
+	languages := []judgeAPI.Language{}
+	for id, name := range judgeAPI.JudgeLanguageDescription {
+		languages = append(languages, judgeAPI.Language{ID: int(id), Name: name})
+	}
+	writeJson(w, 200, languages)
+}
+
+func (s *Server) getAbout(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, 200, map[string]string{"version": "judge0test"})
+}
+
+// must be called with s.mu held
+func (s *Server) store(submission judgeAPI.Submission) string {
+	s.nextToken += 1
+	token := tokenFor(s.nextToken)
+
+	submission.Token = token
+	fs := &fakeSubmission{
+		submission: submission,
+		verdict:    s.verdict(submission),
+	}
+	s.submissions[token] = fs
+
+	if submission.CallbackURL != "" && !fs.verdict.DropCallback {
+		go s.callback(submission.CallbackURL, fs.final())
+	}
+
+	return token
+}
+
+// callback sends what judge zero sends, the run output without the input
+// side, base64 encoded like judge zero always does for callbacks
+func (s *Server) callback(callbackURL string, result judgeAPI.Submission) {
+	encode := base64.StdEncoding.EncodeToString
+	payload := judgeAPI.Submission{
+		Token:         result.Token,
+		Status:        result.Status,
+		Stdout:        encode([]byte(result.Stdout)),
+		Stderr:        encode([]byte(result.Stderr)),
+		CompileOutput: encode([]byte(result.CompileOutput)),
+		Message:       encode([]byte(result.Message)),
+		ExitCode:      result.ExitCode,
+		Time:          result.Time,
+		Memory:        result.Memory,
+	}
+
+	s.mu.Lock()
+	s.requests[CALLBACK] += 1
+	s.mu.Unlock()
+
+	req, err := http.NewRequest("PUT", callbackURL, strings.NewReader(string(payload.JSON())))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+}
+
+func (fs *fakeSubmission) poll() judgeAPI.Submission {
+	fs.polls += 1
+
+	result := fs.submission
+	switch {
+	case fs.polls <= fs.verdict.QueuedPolls:
+		result.Status = status(judgeAPI.IN_QUEUE_STATUS)
+	case fs.polls <= fs.verdict.QueuedPolls+fs.verdict.ProcessingPolls:
+		result.Status = status(judgeAPI.PROCESSING_STATUS)
+	default:
+		result = fs.final()
+	}
+
+	return result
+}
+
+func (fs *fakeSubmission) final() judgeAPI.Submission {
+	result := fs.submission
+	result.Status = status(fs.verdict.Status)
+	result.Stdout = fs.verdict.Stdout
+	result.Stderr = fs.verdict.Stderr
+	result.CompileOutput = fs.verdict.CompileOutput
+	result.Message = fs.verdict.Message
+	result.ExitCode = fs.verdict.ExitCode
+	result.Time = fs.verdict.Time
+	result.Memory = fs.verdict.Memory
+	return result
+}
+
+func status(id judgeAPI.JudgeSubmissionStatusID) judgeAPI.SubmissionStatus {
+	return judgeAPI.SubmissionStatus{
+		ID:          id,
+		Description: judgeAPI.JudgeSubmissionStatusDescription[id],
+	}
+}
+
+func tokenFor(n int) string {
+	return fmt.Sprintf("token-%d", n)
+}
+
+func writeJson(w http.ResponseWriter, code int, payload any) {
+	data, err := json.Marshal(payload)
+	utils.Assert(err, "error converting fake judge zero response to json")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -11,10 +12,16 @@ import (
 	"github.com/Modalessi/nuha-api/internal/utils"
 )
 
-type JudgeAPI struct {
+// judge0Client holds everything both the RapidAPI and the self hosted
+// judge zero backends share, they only differ in base url and auth headers
+type judge0Client struct {
 	baseURL *url.URL
-	apiKey  string
-	host    string
+	headers http.Header
+}
+
+// JudgeAPI is the judge zero client that goes through RapidAPI
+type JudgeAPI struct {
+	judge0Client
 }
 
 func NewJudgeAPI(apiKey string, host string) *JudgeAPI {
@@ -23,27 +30,70 @@ func NewJudgeAPI(apiKey string, host string) *JudgeAPI {
 	baseURL, err := url.Parse(BASE_URL_STRING)
 	utils.Assert(err, "failed parsing Judge api base url")
 
+	headers := http.Header{}
+	headers.Add("x-rapidapi-key", apiKey)
+	headers.Add("x-rapidapi-host", host)
+
 	return &JudgeAPI{
-		baseURL: baseURL,
-		apiKey:  apiKey,
-		host:    host,
+		judge0Client: judge0Client{
+			baseURL: baseURL,
+			headers: headers,
+		},
 	}
 }
 
-func (j *JudgeAPI) PostSubmission(s *Submission) (string, error) {
-	postSubmissionURL := j.baseURL.JoinPath("submissions")
-
-	payload := bytes.NewReader(s.JSON())
-	req, err := http.NewRequest("POST", postSubmissionURL.String(), payload)
+func (c *judge0Client) newRequest(method string, u *url.URL, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
-		return "", fmt.Errorf("error making submission request for judge zero: %w", err)
+		return nil, err
 	}
 
-	req.Header.Add("x-rapidapi-key", j.apiKey)
-	req.Header.Add("x-rapidapi-host", j.host)
+	for key, values := range c.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
 	req.Header.Add("Content-Type", "application/json")
 
+	return req, nil
+}
+
+func (c *judge0Client) do(req *http.Request) (*http.Response, error) {
 	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return nil, &ResponseError{StatusCode: res.StatusCode, Body: string(body)}
+	}
+
+	return res, nil
+}
+
+// ResponseError is a non 2xx answer from judge zero, the body is kept so
+// callers can see what judge zero complained about
+type ResponseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("judge zero responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+func (c *judge0Client) PostSubmission(s *Submission) (string, error) {
+	postSubmissionURL := c.baseURL.JoinPath("submissions")
+
+	payload := bytes.NewReader(s.JSON())
+	req, err := c.newRequest("POST", postSubmissionURL, payload)
+	if err != nil {
+		return "", fmt.Errorf("error making submission request for judge zero: %w", err)
+	}
+
+	res, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("someting went wrong with judge zero sending submission request %v", err)
 	}
@@ -63,20 +113,16 @@ func (j *JudgeAPI) PostSubmission(s *Submission) (string, error) {
 	return resBody.Token, nil
 }
 
-func (j *JudgeAPI) PostBatchSubmission(bs *SubmissionBatch) ([]string, error) {
-	postBatchSubmissionURL := j.baseURL.JoinPath("submissions/batch")
+func (c *judge0Client) PostBatchSubmission(bs *SubmissionBatch) ([]string, error) {
+	postBatchSubmissionURL := c.baseURL.JoinPath("submissions/batch")
 
 	payload := bytes.NewReader(bs.JSON())
-	req, err := http.NewRequest("POST", postBatchSubmissionURL.String(), payload)
+	req, err := c.newRequest("POST", postBatchSubmissionURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("error making batch submission request for judge zero: %w", err)
 	}
 
-	req.Header.Add("x-rapidapi-key", j.apiKey)
-	req.Header.Add("x-rapidapi-host", j.host)
-	req.Header.Add("Content-Type", "application/json")
-
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("someting went wrong with judge zero sending batch submission request %w", err)
 	}
@@ -100,25 +146,21 @@ func (j *JudgeAPI) PostBatchSubmission(bs *SubmissionBatch) ([]string, error) {
 	return tokens, nil
 }
 
-func (j *JudgeAPI) GetBatchSubmissionsResult(tokens []string) ([]Submission, error) {
+func (c *judge0Client) GetBatchSubmissionsResult(tokens []string) ([]Submission, error) {
 	tokensQuery := strings.Join(tokens, ",")
-	postBatchSubmissionURL := j.baseURL.JoinPath("submissions/batch")
+	postBatchSubmissionURL := c.baseURL.JoinPath("submissions/batch")
 	query := postBatchSubmissionURL.Query()
 	query.Add("tokens", tokensQuery)
 	query.Add("base64_encoded", "false")
-	query.Add("fields", "*")
+	query.Add("fields", strings.Join(RESULT_FIELDS, ","))
 	postBatchSubmissionURL.RawQuery = query.Encode()
 
-	req, err := http.NewRequest("GET", postBatchSubmissionURL.String(), nil)
+	req, err := c.newRequest("GET", postBatchSubmissionURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error making get batch submission request for judge zero: %w", err)
 	}
 
-	req.Header.Add("x-rapidapi-key", j.apiKey)
-	req.Header.Add("x-rapidapi-host", j.host)
-	req.Header.Add("Content-Type", "application/json")
-
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("someting went wrong with judge zero sending get batch submission request %w", err)
 	}
@@ -135,3 +177,43 @@ func (j *JudgeAPI) GetBatchSubmissionsResult(tokens []string) ([]Submission, err
 
 	return resultData.Submissions, nil
 }
+
+func (c *judge0Client) GetLanguages() ([]Language, error) {
+	languagesURL := c.baseURL.JoinPath("languages")
+
+	req, err := c.newRequest("GET", languagesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making get languages request for judge zero: %w", err)
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("someting went wrong with judge zero sending get languages request %w", err)
+	}
+	defer res.Body.Close()
+
+	languages := []Language{}
+	err = json.NewDecoder(res.Body).Decode(&languages)
+	if err != nil {
+		return nil, fmt.Errorf("someting went wrong while decoding judge zero get languages response %w", err)
+	}
+
+	return languages, nil
+}
+
+func (c *judge0Client) CheckHealth() error {
+	aboutURL := c.baseURL.JoinPath("about")
+
+	req, err := c.newRequest("GET", aboutURL, nil)
+	if err != nil {
+		return fmt.Errorf("error making health request for judge zero: %w", err)
+	}
+
+	res, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("judge zero is not healthy: %w", err)
+	}
+	res.Body.Close()
+
+	return nil
+}
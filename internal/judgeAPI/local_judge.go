@@ -0,0 +1,580 @@
+//go:build linux
+
+package judgeAPI
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaults are the same ones judge zero uses when a field is not set
+const (
+	LOCAL_JUDGE_QUEUE_SIZE              = 1000
+	LOCAL_JUDGE_DEFAULT_CPU_TIME_LIMIT  = 5      // seconds
+	LOCAL_JUDGE_DEFAULT_WALL_TIME_LIMIT = 10     // seconds
+	LOCAL_JUDGE_DEFAULT_MEMORY_LIMIT    = 128000 // KB
+	LOCAL_JUDGE_DEFAULT_STACK_LIMIT     = 64000  // KB
+	LOCAL_JUDGE_DEFAULT_MAX_PROCESSES   = 60
+	LOCAL_JUDGE_DEFAULT_MAX_FILE_SIZE   = 1024 // KB
+	LOCAL_JUDGE_COMPILE_TIME_LIMIT      = 30   // seconds
+	LOCAL_JUDGE_OUTPUT_LIMIT            = 8 << 20
+	LOCAL_JUDGE_RESULTS_RETENTION       = time.Hour
+)
+
+type localLanguage struct {
+	tool       string
+	sourceFile string                          // empty when the program comes in additional_files
+	compile    func(options []string) []string // nil for interpreted languages
+	run        []string
+	// go reserves a lot of address space on startup, so for it only the data
+	// segment is limited and not the whole address space
+	dataLimit bool
+}
+
+func gccLanguage(compiler string, sourceFile string) localLanguage {
+	return localLanguage{
+		tool:       compiler,
+		sourceFile: sourceFile,
+		compile: func(options []string) []string {
+			cmd := append([]string{compiler, "-O2"}, options...)
+			return append(cmd, "-o", "main", sourceFile, "-lm")
+		},
+		run: []string{"./main"},
+	}
+}
+
+func pythonLanguage(interpreter string) localLanguage {
+	return localLanguage{
+		tool:       interpreter,
+		sourceFile: "main.py",
+		run:        []string{interpreter, "main.py"},
+	}
+}
+
+var localLanguages = map[JudgeLanguage]localLanguage{
+	C_GCC_7:    gccLanguage("gcc", "main.c"),
+	C_GCC_8:    gccLanguage("gcc", "main.c"),
+	C_GCC_9:    gccLanguage("gcc", "main.c"),
+	C_CLANG18:  gccLanguage("clang", "main.c"),
+	CPP_GCC_7:  gccLanguage("g++", "main.cpp"),
+	CPP_GCC_8:  gccLanguage("g++", "main.cpp"),
+	CPP_GCC_9:  gccLanguage("g++", "main.cpp"),
+	PYTHON_2:   pythonLanguage("python2"),
+	PYTHON_3:   pythonLanguage("python3"),
+	PYTHON_311: pythonLanguage("python3"),
+	PYTHON_312: pythonLanguage("python3"),
+	MULTI_FILE_PROGRAM: {
+		tool: "bash",
+		// like judge zero the compile script is optional
+		compile: func(options []string) []string {
+			return []string{"bash", "-c", "[ ! -f compile ] || bash compile"}
+		},
+		run: []string{"bash", "run"},
+	},
+	GO: {
+		tool:       "go",
+		sourceFile: "main.go",
+		compile: func(options []string) []string {
+			cmd := append([]string{"go", "build"}, options...)
+			return append(cmd, "-o", "main", "main.go")
+		},
+		run:       []string{"./main"},
+		dataLimit: true,
+	},
+}
+
+// LocalJudge compiles and runs submissions on this machine, every run gets
+// its own temp dir, rlimits through prlimit(1) and a wall clock watchdog
+type LocalJudge struct {
+	workDir string
+	queue   chan string
+	mu      sync.Mutex
+	runs    map[string]*Submission
+}
+
+func NewLocalJudge(workDir string, workers int) (Judge, error) {
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		return nil, fmt.Errorf("local judge needs prlimit (util-linux) installed: %w", err)
+	}
+
+	err := os.MkdirAll(workDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating local judge work dir: %w", err)
+	}
+
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving local judge work dir: %w", err)
+	}
+
+	lj := &LocalJudge{
+		workDir: absWorkDir,
+		queue:   make(chan string, LOCAL_JUDGE_QUEUE_SIZE),
+		runs:    make(map[string]*Submission),
+	}
+
+	for range max(workers, 1) {
+		go lj.worker()
+	}
+
+	return lj, nil
+}
+
+func (lj *LocalJudge) PostBatchSubmission(bs *SubmissionBatch) ([]string, error) {
+	for _, s := range *bs {
+		if _, ok := localLanguages[JudgeLanguage(s.LanguageID)]; !ok {
+			return nil, fmt.Errorf("local judge does not support language %d", s.LanguageID)
+		}
+	}
+
+	lj.mu.Lock()
+	defer lj.mu.Unlock()
+
+	if len(lj.queue)+len(*bs) > cap(lj.queue) {
+		return nil, fmt.Errorf("local judge queue is full")
+	}
+
+	lj.removeOldRuns()
+
+	tokens := make([]string, len(*bs))
+	for i, s := range *bs {
+		run := s
+		run.Token = uuid.NewString()
+		run.Status = newStatus(IN_QUEUE_STATUS)
+		run.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+		lj.runs[run.Token] = &run
+		tokens[i] = run.Token
+	}
+
+	for _, token := range tokens {
+		lj.queue <- token
+	}
+
+	return tokens, nil
+}
+
+func (lj *LocalJudge) GetBatchSubmissionsResult(tokens []string) ([]Submission, error) {
+	lj.mu.Lock()
+	defer lj.mu.Unlock()
+
+	results := make([]Submission, len(tokens))
+	for i, token := range tokens {
+		run, ok := lj.runs[token]
+		if !ok {
+			results[i] = Submission{
+				Token:   token,
+				Status:  newStatus(INTERNAL_ERROR_STATUS),
+				Message: "unknown token",
+			}
+			continue
+		}
+		results[i] = *run
+	}
+
+	return results, nil
+}
+
+func (lj *LocalJudge) GetLanguages() ([]Language, error) {
+	languages := []Language{}
+	for id, lang := range localLanguages {
+		if _, err := exec.LookPath(lang.tool); err != nil {
+			continue
+		}
+		languages = append(languages, Language{ID: int(id), Name: JudgeLanguageDescription[id]})
+	}
+
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].ID < languages[j].ID
+	})
+
+	return languages, nil
+}
+
+func (lj *LocalJudge) CheckHealth() error {
+	dir, err := os.MkdirTemp(lj.workDir, "health-")
+	if err != nil {
+		return fmt.Errorf("local judge work dir is not writable: %w", err)
+	}
+	return os.RemoveAll(dir)
+}
+
+func (lj *LocalJudge) worker() {
+	for token := range lj.queue {
+		lj.mu.Lock()
+		run := *lj.runs[token]
+		lj.runs[token].Status = newStatus(PROCESSING_STATUS)
+		lj.mu.Unlock()
+
+		result := lj.execute(run)
+		result.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+
+		lj.mu.Lock()
+		lj.runs[token] = &result
+		lj.mu.Unlock()
+	}
+}
+
+// must be called with lj.mu held
+func (lj *LocalJudge) removeOldRuns() {
+	for token, run := range lj.runs {
+		if run.FinishedAt == "" {
+			continue
+		}
+
+		finishedAt, err := time.Parse(time.RFC3339, run.FinishedAt)
+		if err == nil && time.Since(finishedAt) > LOCAL_JUDGE_RESULTS_RETENTION {
+			delete(lj.runs, token)
+		}
+	}
+}
+
+func (lj *LocalJudge) execute(s Submission) Submission {
+	lang := localLanguages[JudgeLanguage(s.LanguageID)]
+
+	dir, err := os.MkdirTemp(lj.workDir, "run-")
+	if err != nil {
+		return internalErrorResult(s, err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = writeAdditionalFiles(dir, s.AdditionalFiles)
+	if err != nil {
+		return internalErrorResult(s, err)
+	}
+
+	if lang.sourceFile != "" {
+		err = os.WriteFile(filepath.Join(dir, lang.sourceFile), []byte(s.SourceCode), 0644)
+		if err != nil {
+			return internalErrorResult(s, err)
+		}
+	}
+
+	if lang.compile != nil {
+		compileLimits := sandboxLimits{
+			cpuTime:  LOCAL_JUDGE_COMPILE_TIME_LIMIT,
+			wallTime: LOCAL_JUDGE_COMPILE_TIME_LIMIT * 2,
+		}
+
+		compileCmd := lang.compile(strings.Fields(s.CompilerOptions))
+		res, err := lj.runSandboxed(dir, compileCmd, "", compileLimits)
+		if err != nil {
+			return internalErrorResult(s, err)
+		}
+
+		s.CompileOutput = res.stdout + res.stderr
+		if res.timedOut || !res.state.Success() {
+			s.Status = newStatus(COMPILATION_ERROR_STATUS)
+			return s
+		}
+	}
+
+	limits := submissionLimits(&s)
+	limits.dataOnly = lang.dataLimit
+	runCmd := append(append([]string{}, lang.run...), strings.Fields(s.CommandLineArguments)...)
+
+	res, err := lj.runSandboxed(dir, runCmd, s.Stdin, limits)
+	if err != nil {
+		return internalErrorResult(s, err)
+	}
+
+	s.Stdout = res.stdout
+	s.Stderr = res.stderr
+	s.Time = strconv.FormatFloat(res.time, 'f', 3, 64)
+	s.WallTime = strconv.FormatFloat(res.wallTime, 'f', 3, 64)
+	s.Memory = res.memory
+
+	ws, _ := res.state.Sys().(syscall.WaitStatus)
+	if ws.Signaled() {
+		s.ExitSignal = int(ws.Signal())
+	} else {
+		s.ExitCode = ws.ExitStatus()
+	}
+
+	statusID, message := localVerdict(res, ws, limits)
+	s.Message = message
+
+	if statusID == ACCEPTED_STATUS && s.ExpectedOutput != "" && !outputsMatch(s.Stdout, s.ExpectedOutput) {
+		statusID = WRONG_ANSWER_STATUS
+	}
+
+	s.Status = newStatus(statusID)
+	return s
+}
+
+// writeAdditionalFiles extracts additional_files into the run directory like
+// judge zero does, names that would land outside of it are refused
+func writeAdditionalFiles(dir string, encoded string) error {
+	if encoded == "" {
+		return nil
+	}
+
+	files, err := DecodeAdditionalFiles(encoded)
+	if err != nil {
+		return err
+	}
+
+	for name, content := range files {
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("additional file %q is outside the run directory", name)
+		}
+
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func submissionLimits(s *Submission) sandboxLimits {
+	limits := sandboxLimits{
+		cpuTime:   LOCAL_JUDGE_DEFAULT_CPU_TIME_LIMIT,
+		wallTime:  LOCAL_JUDGE_DEFAULT_WALL_TIME_LIMIT,
+		memory:    LOCAL_JUDGE_DEFAULT_MEMORY_LIMIT,
+		stack:     LOCAL_JUDGE_DEFAULT_STACK_LIMIT,
+		processes: LOCAL_JUDGE_DEFAULT_MAX_PROCESSES,
+		fileSize:  LOCAL_JUDGE_DEFAULT_MAX_FILE_SIZE,
+	}
+
+	if t, err := strconv.ParseFloat(s.CPUTimeLimit, 64); err == nil && t > 0 {
+		limits.cpuTime = t
+	}
+	if t, err := strconv.ParseFloat(s.CPUExtraTime, 64); err == nil && t > 0 {
+		limits.cpuTime += t
+	}
+	if t, err := strconv.ParseFloat(s.WallTimeLimit, 64); err == nil && t > 0 {
+		limits.wallTime = t
+	}
+	limits.wallTime = max(limits.wallTime, limits.cpuTime*2)
+
+	if s.MemoryLimit > 0 {
+		limits.memory = s.MemoryLimit
+	}
+	if s.StackLimit > 0 {
+		limits.stack = s.StackLimit
+	}
+	if s.MaxProcessesAndOrThreads > 0 {
+		limits.processes = s.MaxProcessesAndOrThreads
+	}
+	if s.MaxFileSize > 0 {
+		limits.fileSize = s.MaxFileSize
+	}
+
+	return limits
+}
+
+func localVerdict(res *sandboxResult, ws syscall.WaitStatus, limits sandboxLimits) (JudgeSubmissionStatusID, string) {
+	if res.timedOut || res.time > limits.cpuTime {
+		return TIME_LIMIT_EXCEEDED_STATUS, "Time limit exceeded"
+	}
+
+	if !res.state.Success() && outOfMemory(res, limits) {
+		return RUNTIME_ERROR_NZEC_STATUS, MEMORY_LIMIT_EXCEEDED_MESSAGE
+	}
+
+	if ws.Signaled() {
+		signal := ws.Signal()
+		message := fmt.Sprintf("Exited with signal %d (%s)", int(signal), signal.String())
+
+		switch signal {
+		case syscall.SIGXCPU:
+			return TIME_LIMIT_EXCEEDED_STATUS, "Time limit exceeded"
+		case syscall.SIGSEGV:
+			return RUNTIME_ERROR_SIGSEGV_STATUS, message
+		case syscall.SIGXFSZ:
+			return RUNTIME_ERROR_SIGXFSZ_STATUS, message
+		case syscall.SIGFPE:
+			return RUNTIME_ERROR_SIGFPE_STATUS, message
+		case syscall.SIGABRT:
+			return RUNTIME_ERROR_SIGABRT_STATUS, message
+		default:
+			return RUNTIME_ERROR_OTHER_STATUS, message
+		}
+	}
+
+	if ws.ExitStatus() != 0 {
+		return RUNTIME_ERROR_NZEC_STATUS, fmt.Sprintf("Exited with error status %d", ws.ExitStatus())
+	}
+
+	return ACCEPTED_STATUS, ""
+}
+
+// allocation failures the runtimes print when they hit the address space limit
+var outOfMemoryMarkers = []string{
+	"MemoryError",
+	"std::bad_alloc",
+	"out of memory",
+	"Cannot allocate memory",
+	"OutOfMemoryError",
+}
+
+// a failed allocation does not kill the program like the cgroup limit of judge
+// zero does, it fails however the language handles it, so a run that failed
+// at the memory limit or with an allocation error is treated as going over it
+func outOfMemory(res *sandboxResult, limits sandboxLimits) bool {
+	if limits.memory <= 0 {
+		return false
+	}
+	if res.memory >= limits.memory {
+		return true
+	}
+
+	for _, marker := range outOfMemoryMarkers {
+		if strings.Contains(res.stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// same loose comparison judge zero does, trailing whitespace is ignored
+func outputsMatch(stdout string, expectedOutput string) bool {
+	return strings.TrimRight(stdout, " \t\r\n") == strings.TrimRight(expectedOutput, " \t\r\n")
+}
+
+func internalErrorResult(s Submission, err error) Submission {
+	s.Status = newStatus(INTERNAL_ERROR_STATUS)
+	s.Message = err.Error()
+	return s
+}
+
+func newStatus(id JudgeSubmissionStatusID) SubmissionStatus {
+	return SubmissionStatus{
+		ID:          id,
+		Description: JudgeSubmissionStatusDescription[id],
+	}
+}
+
+type sandboxLimits struct {
+	cpuTime   float64 // seconds
+	wallTime  float64 // seconds
+	memory    float64 // KB, 0 means unlimited
+	stack     int     // KB, 0 means unlimited
+	processes int     // 0 means unlimited
+	fileSize  int     // KB, 0 means unlimited
+	dataOnly  bool    // limit the data segment instead of the address space
+}
+
+type sandboxResult struct {
+	stdout   string
+	stderr   string
+	state    *os.ProcessState
+	timedOut bool
+	time     float64 // cpu seconds
+	wallTime float64 // seconds
+	memory   float64 // KB
+}
+
+func (lj *LocalJudge) runSandboxed(dir string, argv []string, stdin string, limits sandboxLimits) (*sandboxResult, error) {
+	// the soft cpu limit sends SIGXCPU, the hard one a second later is SIGKILL
+	cpuLimit := int(math.Ceil(limits.cpuTime))
+	prlimitArgs := []string{fmt.Sprintf("--cpu=%d:%d", cpuLimit, cpuLimit+1)}
+	if limits.memory > 0 && limits.dataOnly {
+		prlimitArgs = append(prlimitArgs, fmt.Sprintf("--data=%d", int64(limits.memory)*1024))
+	} else if limits.memory > 0 {
+		prlimitArgs = append(prlimitArgs, fmt.Sprintf("--as=%d", int64(limits.memory)*1024))
+	}
+	if limits.stack > 0 {
+		prlimitArgs = append(prlimitArgs, fmt.Sprintf("--stack=%d", limits.stack*1024))
+	}
+	if limits.processes > 0 {
+		prlimitArgs = append(prlimitArgs, fmt.Sprintf("--nproc=%d", limits.processes))
+	}
+	if limits.fileSize > 0 {
+		prlimitArgs = append(prlimitArgs, fmt.Sprintf("--fsize=%d", limits.fileSize*1024))
+	}
+	prlimitArgs = append(prlimitArgs, "--")
+	prlimitArgs = append(prlimitArgs, argv...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(limits.wallTime*float64(time.Second)))
+	defer cancel()
+
+	stdout := &cappedBuffer{limit: LOCAL_JUDGE_OUTPUT_LIMIT}
+	stderr := &cappedBuffer{limit: LOCAL_JUDGE_OUTPUT_LIMIT}
+
+	cmd := exec.CommandContext(ctx, "prlimit", prlimitArgs...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"LANG=C.UTF-8",
+		"GOCACHE=" + filepath.Join(lj.workDir, "go-cache"),
+		"GOPATH=" + filepath.Join(dir, "go"),
+		"GOTOOLCHAIN=local",
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// kill the whole process group so forked children die with it
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	wallTime := time.Since(start)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("error running %v: %w", argv, err)
+	}
+
+	res := &sandboxResult{
+		stdout:   stdout.String(),
+		stderr:   stderr.String(),
+		state:    cmd.ProcessState,
+		timedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+		time:     (cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()).Seconds(),
+		wallTime: wallTime.Seconds(),
+	}
+
+	if usage, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage); ok {
+		res.memory = float64(usage.Maxrss)
+	}
+
+	return res, nil
+}
+
+// cappedBuffer keeps the first limit bytes and silently drops the rest,
+// a program printing forever should not take the server memory with it
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	remaining := c.limit - c.buf.Len()
+	if remaining > 0 {
+		c.buf.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+func (c *cappedBuffer) String() string {
+	return c.buf.String()
+}
+
+var _ Judge = (*LocalJudge)(nil)
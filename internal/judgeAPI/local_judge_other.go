@@ -0,0 +1,9 @@
+//go:build !linux
+
+package judgeAPI
+
+import "fmt"
+
+func NewLocalJudge(workDir string, workers int) (Judge, error) {
+	return nil, fmt.Errorf("the local judge only runs on linux")
+}
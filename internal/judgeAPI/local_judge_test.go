@@ -0,0 +1,235 @@
+//go:build linux
+
+package judgeAPI
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+func runLocally(t *testing.T, judge Judge, submission *Submission, testcases ...models.Testcase) []Submission {
+	t.Helper()
+
+	tokens, err := judge.PostBatchSubmission(submission.GenerateBatchFromTestCases(testcases...))
+	if err != nil {
+		t.Fatalf("error posting batch: %v", err)
+	}
+
+	deadline := time.Now().Add(60 * time.Second)
+	for time.Now().Before(deadline) {
+		results, err := judge.GetBatchSubmissionsResult(tokens)
+		if err != nil {
+			t.Fatalf("error getting results: %v", err)
+		}
+
+		done := true
+		for _, r := range results {
+			if r.Status.ID == IN_QUEUE_STATUS || r.Status.ID == PROCESSING_STATUS {
+				done = false
+			}
+		}
+		if done {
+			return results
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("local judge did not finish in time")
+	return nil
+}
+
+func newTestLocalJudge(t *testing.T, tools ...string) Judge {
+	t.Helper()
+
+	for _, tool := range append(tools, "prlimit") {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not installed", tool)
+		}
+	}
+
+	judge, err := NewLocalJudge(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("error creating local judge: %v", err)
+	}
+	return judge
+}
+
+func TestLocalJudgeCpp(t *testing.T) {
+	judge := newTestLocalJudge(t, "g++")
+
+	sourceCode := "#include <iostream>\n"
+	sourceCode += "int main() { long long a, b; std::cin >> a >> b; std::cout << a + b << std::endl; }\n"
+
+	submission := NewSubmission(sourceCode, CPP_GCC_9)
+	results := runLocally(t, judge, submission, *models.NewTestCase("5 6", "11"), *models.NewTestCase("1 2", "4"))
+
+	if results[0].Status.ID != ACCEPTED_STATUS {
+		t.Fatalf("wanted accepted on first test, got %v (%s)", results[0].Status, results[0].CompileOutput)
+	}
+	if results[1].Status.ID != WRONG_ANSWER_STATUS {
+		t.Fatalf("wanted wrong answer on second test, got %v", results[1].Status)
+	}
+}
+
+func TestLocalJudgeCompilationError(t *testing.T) {
+	judge := newTestLocalJudge(t, "gcc")
+
+	submission := NewSubmission("int main() { return x; }", C_GCC_9)
+	results := runLocally(t, judge, submission, *models.NewTestCase("", ""))
+
+	if results[0].Status.ID != COMPILATION_ERROR_STATUS {
+		t.Fatalf("wanted compilation error, got %v", results[0].Status)
+	}
+	if results[0].CompileOutput == "" {
+		t.Fatalf("wanted compiler output to be captured")
+	}
+}
+
+func TestLocalJudgeLimits(t *testing.T) {
+	judge := newTestLocalJudge(t, "python3")
+
+	loop := NewSubmission("while True:\n    pass\n", PYTHON_3)
+	loop.SetCPUTimeLimit(1)
+
+	crash := NewSubmission("import sys\nsys.exit(3)\n", PYTHON_3)
+
+	results := runLocally(t, judge, loop, *models.NewTestCase("", ""))
+	if results[0].Status.ID != TIME_LIMIT_EXCEEDED_STATUS {
+		t.Fatalf("wanted time limit exceeded, got %v", results[0].Status)
+	}
+
+	results = runLocally(t, judge, crash, *models.NewTestCase("", ""))
+	if results[0].Status.ID != RUNTIME_ERROR_NZEC_STATUS || results[0].ExitCode != 3 {
+		t.Fatalf("wanted runtime error with exit code 3, got %v exit %d", results[0].Status, results[0].ExitCode)
+	}
+
+	hog := NewSubmission("x = bytearray(256 * 1024 * 1024)\n", PYTHON_3)
+	hog.SetMemoryLimit(64000)
+
+	results = runLocally(t, judge, hog, *models.NewTestCase("", ""))
+	if results[0].Status.ID != RUNTIME_ERROR_NZEC_STATUS || results[0].Message != MEMORY_LIMIT_EXCEEDED_MESSAGE {
+		t.Fatalf("wanted memory limit exceeded, got %v %q", results[0].Status, results[0].Message)
+	}
+}
+
+func TestLocalJudgeAdditionalFiles(t *testing.T) {
+	judge := newTestLocalJudge(t, "python3")
+
+	files, err := EncodeAdditionalFiles(map[string][]byte{"data/answer.txt": []byte("42\n")})
+	if err != nil {
+		t.Fatalf("error encoding additional files: %v", err)
+	}
+
+	submission := NewSubmission("import sys\nprint(open(sys.argv[1]).read().strip())\n", PYTHON_3)
+	submission.SetAdditionalFiles(files)
+	submission.SetCommandLineArguments("data/answer.txt")
+
+	results := runLocally(t, judge, submission, *models.NewTestCase("", "42"))
+	if results[0].Status.ID != ACCEPTED_STATUS {
+		t.Fatalf("wanted accepted, got %v (%s)", results[0].Status, results[0].Stderr)
+	}
+}
+
+func TestLocalJudgeInteractive(t *testing.T) {
+	judge := newTestLocalJudge(t, "python3", "bash", "mkfifo")
+
+	interactor := Program{Language: PYTHON_3, Code: `import sys
+n = int(open(sys.argv[1]).read())
+for _ in range(10):
+    guess = int(input())
+    if guess == n:
+        print("=", flush=True)
+        sys.exit(0)
+    print("<" if n < guess else ">", flush=True)
+sys.stderr.write("too many guesses")
+sys.exit(1)
+`}
+	binarySearch := Program{Language: PYTHON_3, Code: `lo, hi = 1, 100
+while True:
+    mid = (lo + hi) // 2
+    print(mid, flush=True)
+    answer = input()
+    if answer == "=":
+        break
+    if answer == "<":
+        hi = mid - 1
+    else:
+        lo = mid + 1
+`}
+	guessOne := Program{Language: PYTHON_3, Code: "while True:\n    print(1, flush=True)\n    input()\n"}
+
+	submission, err := NewInteractiveSubmission(binarySearch, interactor)
+	if err != nil {
+		t.Fatalf("error making interactive submission: %v", err)
+	}
+	results := runLocally(t, judge, submission, *models.NewTestCase("37", ""))
+	if results[0].Status.ID != ACCEPTED_STATUS {
+		t.Fatalf("wanted accepted, got %v (%s)", results[0].Status, results[0].Stderr)
+	}
+	if !strings.HasPrefix(results[0].Stdout, "> 50\n< <\n> 25\n< >\n") || SolutionOutput(results[0].Stdout) != "50\n25\n37\n" {
+		t.Fatalf("got query log %q", results[0].Stdout)
+	}
+
+	submission, err = NewInteractiveSubmission(guessOne, interactor)
+	if err != nil {
+		t.Fatalf("error making interactive submission: %v", err)
+	}
+	results = runLocally(t, judge, submission, *models.NewTestCase("37", ""))
+	if results[0].Status.ID != RUNTIME_ERROR_NZEC_STATUS || results[0].ExitCode != INTERACTOR_WRONG_ANSWER {
+		t.Fatalf("wanted the interactor wrong answer, got %v exit %d", results[0].Status, results[0].ExitCode)
+	}
+	if results[0].Stderr != "too many guesses" {
+		t.Fatalf("got stderr %q, wanted the interactor comment", results[0].Stderr)
+	}
+
+	// the solution goes looking for the test and the interactor first
+	snooping := binarySearch
+	snooping.Code = `import os
+for name in ["../input.txt", "../test_input", "../interactor/main.py", "../query_log.txt", "../interactor_stderr.txt"]:
+    if os.path.exists(name):
+        print("found " + name, flush=True)
+` + binarySearch.Code
+
+	submission, err = NewInteractiveSubmission(snooping, interactor)
+	if err != nil {
+		t.Fatalf("error making interactive submission: %v", err)
+	}
+	results = runLocally(t, judge, submission, *models.NewTestCase("37", ""))
+	if results[0].Status.ID != ACCEPTED_STATUS || strings.Contains(results[0].Stdout, "found") {
+		t.Fatalf("solution could read the test or the interactor, got %v with query log %q", results[0].Status, results[0].Stdout)
+	}
+}
+
+func TestLocalJudgeMultiFileProgram(t *testing.T) {
+	judge := newTestLocalJudge(t, "g++", "bash")
+
+	program := Program{
+		Language: CPP_GCC_9,
+		Entry:    "app.cpp",
+		Code:     "#include <iostream>\n#include \"lib/add.h\"\nint main() { int a, b; std::cin >> a >> b; std::cout << add(a, b) << std::endl; }\n",
+		Files: map[string][]byte{
+			"lib/add.h":   []byte("int add(int a, int b);\n"),
+			"lib/add.cpp": []byte("#include \"add.h\"\nint add(int a, int b) { return a + b; }\n"),
+		},
+	}
+	submission, err := NewProgramSubmission(program)
+	if err != nil {
+		t.Fatalf("error making program submission: %v", err)
+	}
+
+	results := runLocally(t, judge, submission, *models.NewTestCase("1 2", "3"))
+	if results[0].Status.ID != ACCEPTED_STATUS || strings.TrimSpace(results[0].Stdout) != "3" {
+		t.Fatalf("wanted accepted with 3, got %v %q (%s %s)", results[0].Status, results[0].Stdout, results[0].CompileOutput, results[0].Stderr)
+	}
+
+	program.Files = map[string][]byte{"../escape.h": nil}
+	_, err = NewProgramSubmission(program)
+	if err == nil {
+		t.Fatalf("file outside the program dir was accepted")
+	}
+}
@@ -0,0 +1,149 @@
+package judgeAPI
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// judge zero keeps its compilers outside of PATH, scripts of multi-file
+// programs put them back. on the local judge these dirs do not exist and
+// the tools on PATH are used
+const SCRIPT_PATH = "/usr/local/gcc-9.2.0/bin:/usr/local/python-3.8.1/bin:$PATH"
+
+// replaced with the entry file name in scriptLanguage commands
+const entryPlaceholder = "{entry}"
+
+// scriptLanguage is how a compile or run script builds and starts a
+// program of a language from inside the program dir
+type scriptLanguage struct {
+	sourceFile string
+	compile    string // empty for interpreted languages
+	run        string
+}
+
+// gccScript builds every source of the program, so the other files can be
+// modules of it and not only headers
+func gccScript(compiler string, sourceFile string) scriptLanguage {
+	return scriptLanguage{
+		sourceFile: sourceFile,
+		compile:    fmt.Sprintf("%s -O2 -o main $(find . -name '*%s') -lm", compiler, path.Ext(sourceFile)),
+		run:        "./main",
+	}
+}
+
+var scriptLanguages = map[JudgeLanguage]scriptLanguage{
+	C_GCC_7:    gccScript("gcc", "main.c"),
+	C_GCC_8:    gccScript("gcc", "main.c"),
+	C_GCC_9:    gccScript("gcc", "main.c"),
+	CPP_GCC_7:  gccScript("g++", "main.cpp"),
+	CPP_GCC_8:  gccScript("g++", "main.cpp"),
+	CPP_GCC_9:  gccScript("g++", "main.cpp"),
+	PYTHON_3:   {sourceFile: "main.py", run: "python3 " + entryPlaceholder},
+	PYTHON_311: {sourceFile: "main.py", run: "python3 " + entryPlaceholder},
+	PYTHON_312: {sourceFile: "main.py", run: "python3 " + entryPlaceholder},
+}
+
+const programScript = `#!/bin/bash
+export PATH="%s"
+cd program && %s
+`
+
+// Program is source code with the files it needs next to it
+type Program struct {
+	Language JudgeLanguage
+	Code     string
+	Files    map[string][]byte
+	// file name of Code, the language source file when empty
+	Entry string
+}
+
+// SupportsScripts reports if programs of language can be part of a multi-file program
+func SupportsScripts(language JudgeLanguage) bool {
+	_, ok := scriptLanguages[language]
+	return ok
+}
+
+// Validate checks the file names of the program stay inside its dir and are
+// safe to put in a script, and that the entry point is a source file of the
+// program language
+func (p Program) Validate() error {
+	if p.Entry != "" && !localFileName(p.Entry) {
+		return fmt.Errorf("entry point %q is not a relative file name", p.Entry)
+	}
+	if lang, ok := scriptLanguages[p.Language]; ok && p.Entry != "" && path.Ext(p.Entry) != path.Ext(lang.sourceFile) {
+		return fmt.Errorf("entry point %q of a %s program must be a %s file", p.Entry, JudgeLanguageDescription[p.Language], path.Ext(lang.sourceFile))
+	}
+	for name := range p.Files {
+		if !localFileName(name) {
+			return fmt.Errorf("file %q is not a relative file name", name)
+		}
+		if name == p.Entry {
+			return fmt.Errorf("file %q is the entry point, it is the code of the program", name)
+		}
+	}
+	return nil
+}
+
+// names end up in bash commands unquoted, only plain characters are allowed
+var fileNamePattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+func localFileName(name string) bool {
+	return fileNamePattern.MatchString(name) && path.Clean(name) == name && !path.IsAbs(name) && name != ".." && !strings.HasPrefix(name, "../")
+}
+
+// addTo puts the program and its files under dir in files, it returns the
+// commands that compile and run it from dir
+func (p Program) addTo(files map[string][]byte, dir string) (compile string, run string, err error) {
+	lang, ok := scriptLanguages[p.Language]
+	if !ok {
+		return "", "", fmt.Errorf("language %d can not be run from a script", p.Language)
+	}
+	err = p.Validate()
+	if err != nil {
+		return "", "", err
+	}
+
+	entry := p.Entry
+	if entry == "" {
+		entry = lang.sourceFile
+	}
+
+	for name, content := range p.Files {
+		files[path.Join(dir, name)] = content
+	}
+	files[path.Join(dir, entry)] = []byte(p.Code)
+
+	compile = strings.ReplaceAll(lang.compile, entryPlaceholder, entry)
+	if compile == "" {
+		compile = "true"
+	}
+	return compile, strings.ReplaceAll(lang.run, entryPlaceholder, entry), nil
+}
+
+// NewProgramSubmission runs p like NewSubmission does, programs with more
+// than one file become a multi-file program with scripts that build and
+// run them
+func NewProgramSubmission(p Program) (*Submission, error) {
+	if len(p.Files) == 0 && p.Entry == "" {
+		return NewSubmission(p.Code, p.Language), nil
+	}
+
+	files := map[string][]byte{}
+	compile, run, err := p.addTo(files, "program")
+	if err != nil {
+		return nil, err
+	}
+	files["compile"] = []byte(fmt.Sprintf(programScript, SCRIPT_PATH, compile))
+	files["run"] = []byte(fmt.Sprintf(programScript, SCRIPT_PATH, run))
+
+	encoded, err := EncodeAdditionalFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding program files: %w", err)
+	}
+
+	submission := NewSubmission("", MULTI_FILE_PROGRAM)
+	submission.SetAdditionalFiles(encoded)
+	return submission, nil
+}
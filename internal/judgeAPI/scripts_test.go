@@ -0,0 +1,25 @@
+package judgeAPI
+
+import "testing"
+
+func TestProgramValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		program Program
+		valid   bool
+	}{
+		{"entry in a dir", Program{Language: CPP_GCC_9, Entry: "src/app.cpp", Files: map[string][]byte{"src/lib.h": nil}}, true},
+		{"default entry", Program{Language: PYTHON_3, Files: map[string][]byte{"util.py": nil}}, true},
+		{"entry outside the dir", Program{Language: PYTHON_3, Entry: "../main.py"}, false},
+		{"shell in the entry", Program{Language: PYTHON_3, Entry: "main.py;rm -rf ~"}, false},
+		{"space in a file", Program{Language: PYTHON_3, Entry: "main.py", Files: map[string][]byte{"my util.py": nil}}, false},
+		{"entry the compiler skips", Program{Language: CPP_GCC_9, Entry: "main.cc"}, false},
+		{"entry of another language", Program{Language: PYTHON_3, Entry: "main.cpp"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.program.Validate(); (err == nil) != tt.valid {
+			t.Fatalf("%s got error %v, wanted valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
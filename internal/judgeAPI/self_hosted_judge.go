@@ -0,0 +1,39 @@
+package judgeAPI
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SelfHostedJudgeAPI talks to our own judge zero instance, it uses the
+// AUTHN_HEADER / AUTHZ_HEADER tokens judge zero is configured with
+type SelfHostedJudgeAPI struct {
+	judge0Client
+}
+
+func NewSelfHostedJudgeAPI(baseURL string, authToken string, authUser string) (*SelfHostedJudgeAPI, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid judge zero base url %q: %w", baseURL, err)
+	}
+
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return nil, fmt.Errorf("invalid judge zero base url %q: scheme and host are required", baseURL)
+	}
+
+	headers := http.Header{}
+	if authToken != "" {
+		headers.Add("X-Auth-Token", authToken)
+	}
+	if authUser != "" {
+		headers.Add("X-Auth-User", authUser)
+	}
+
+	return &SelfHostedJudgeAPI{
+		judge0Client: judge0Client{
+			baseURL: parsedURL,
+			headers: headers,
+		},
+	}, nil
+}
@@ -1,7 +1,9 @@
 package judgeAPI
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"strconv"
 
 	"github.com/Modalessi/nuha-api/internal/models"
@@ -37,13 +39,17 @@ type Submission struct {
 	Message                              string           `json:"message,omitempty"`
 	ExitCode                             int              `json:"exit_code,omitempty"`
 	ExitSignal                           int              `json:"exit_signal,omitempty"`
-	Status                               SubmissionStatus `json:"status,omitempty"`
+	Status                               SubmissionStatus `json:"status,omitzero"`
 	CreatedAt                            string           `json:"created_at,omitempty"`
 	FinishedAt                           string           `json:"finished_at,omitempty"`
 	Token                                string           `json:"token,omitempty"`
 	Time                                 string           `json:"time,omitempty"`
 	WallTime                             string           `json:"wall_time,omitempty"`
 	Memory                               float64          `json:"memory,omitempty"`
+	// not a judge zero field, nuha moves the exchange of interactive runs here
+	QueryLog string `json:"query_log,omitempty"`
+	// not one either, the part of the test a checker gave a partially correct run
+	Score *float64 `json:"score,omitempty"`
 }
 
 type SubmissionStatus struct {
@@ -144,6 +150,40 @@ func (s *Submission) GenerateBatchFromTestCases(testcases ...models.Testcase) *S
 	return &batch
 }
 
+// Chunks splits the batch into batches of at most size submissions, in order.
+// a size that is not positive keeps the whole batch as one chunk
+func (sb *SubmissionBatch) Chunks(size int) []*SubmissionBatch {
+	if size <= 0 {
+		return []*SubmissionBatch{sb}
+	}
+
+	chunks := []*SubmissionBatch{}
+	for start := 0; start < len(*sb); start += size {
+		chunk := (*sb)[start:min(start+size, len(*sb))]
+		chunks = append(chunks, &chunk)
+	}
+	return chunks
+}
+
+// DecodeCallback decodes the texts of a result judge zero PUT to the callback
+// url, callbacks are always base64 whatever the submission was sent with
+func (s *Submission) DecodeCallback() error {
+	fields := map[string]*string{
+		"stdout":         &s.Stdout,
+		"stderr":         &s.Stderr,
+		"compile_output": &s.CompileOutput,
+		"message":        &s.Message,
+	}
+	for name, field := range fields {
+		decoded, err := base64.StdEncoding.DecodeString(*field)
+		if err != nil {
+			return fmt.Errorf("callback %s is not base64: %w", name, err)
+		}
+		*field = string(decoded)
+	}
+	return nil
+}
+
 func (s *Submission) JSON() []byte {
 	data, err := json.Marshal(s)
 	utils.Assert(err, "error converting submission struct to json")
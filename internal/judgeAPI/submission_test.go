@@ -2,6 +2,7 @@ package judgeAPI
 
 import (
 	"bytes"
+	"strconv"
 	"testing"
 
 	"github.com/Modalessi/nuha-api/internal/models"
@@ -83,3 +84,32 @@ func TestGenerateBatchJson(t *testing.T) {
 	}
 
 }
+
+func TestBatchChunks(t *testing.T) {
+	submission := NewSubmission("print(input())", PYTHON_3)
+
+	testcases := []models.Testcase{}
+	for i := range 45 {
+		testcases = append(testcases, *models.NewTestCase(strconv.Itoa(i), strconv.Itoa(i)))
+	}
+	batch := submission.GenerateBatchFromTestCases(testcases...)
+
+	chunks := batch.Chunks(MAX_BATCH_SIZE)
+	if len(chunks) != 3 || len(*chunks[0]) != 20 || len(*chunks[2]) != 5 {
+		t.Fatalf("45 submissions were split into %d chunks, wanted 20, 20 and 5", len(chunks))
+	}
+
+	i := 0
+	for _, chunk := range chunks {
+		for _, s := range *chunk {
+			if s.Stdin != strconv.Itoa(i) {
+				t.Fatalf("submission %d is out of order, got stdin %q", i, s.Stdin)
+			}
+			i += 1
+		}
+	}
+
+	if chunks := batch.Chunks(0); len(chunks) != 1 || len(*chunks[0]) != 45 {
+		t.Fatalf("got %d chunks for size 0, wanted the whole batch", len(chunks))
+	}
+}
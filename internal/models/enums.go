@@ -11,4 +11,76 @@ const (
 	COMPILATION_ERROR_SUBMISSION_STATUS SubmissionStatus = "COMPILATION ERROR"
 	RUNTIME_ERROR_SUBMISSION_STATUS     SubmissionStatus = "RUNTIME ERROR"
 	SERVER_ERROR_SUBMISSION_STATUS      SubmissionStatus = "SERVER ERROR"
+	JUDGE_TIMEOUT_SUBMISSION_STATUS     SubmissionStatus = "JUDGE TIMEOUT"
+)
+
+type JudgingMode string
+
+const (
+	// every test runs and the verdict looks at all of them
+	ALL_TESTS_JUDGING_MODE JudgingMode = "ALL_TESTS"
+	// icpc style, tests run in order and judging stops at the first failure
+	STOP_ON_FIRST_FAILURE_JUDGING_MODE JudgingMode = "STOP_ON_FIRST_FAILURE"
+)
+
+type ScoringMode string
+
+const (
+	// a group scores its points only when every one of its tests passed
+	SUM_SCORING_MODE ScoringMode = "SUM"
+	// a group scores its points times the lowest score of its tests
+	MIN_SCORING_MODE ScoringMode = "MIN"
+)
+
+type ProblemType string
+
+const (
+	// the solution reads the test from stdin and its output is compared or checked
+	STANDARD_PROBLEM_TYPE ProblemType = "STANDARD"
+	// the solution talks to the problem interactor, which decides the verdict
+	INTERACTIVE_PROBLEM_TYPE ProblemType = "INTERACTIVE"
+	// the solution is only a function, the harness of its language drives it
+	FUNCTION_PROBLEM_TYPE ProblemType = "FUNCTION"
+	// participants upload the output of every test, nothing is run
+	OUTPUT_ONLY_PROBLEM_TYPE ProblemType = "OUTPUT_ONLY"
+)
+
+var ProblemTypes = []ProblemType{
+	STANDARD_PROBLEM_TYPE,
+	INTERACTIVE_PROBLEM_TYPE,
+	FUNCTION_PROBLEM_TYPE,
+	OUTPUT_ONLY_PROBLEM_TYPE,
+}
+
+type ComparisonMode string
+
+const (
+	// the output must be byte for byte the expected one
+	EXACT_COMPARISON_MODE ComparisonMode = "EXACT"
+	// trailing spaces on each line and trailing empty lines are ignored
+	TRAILING_WHITESPACE_COMPARISON_MODE ComparisonMode = "TRAILING_WHITESPACE"
+	// outputs are compared token by token, any whitespace separates tokens
+	TOKENS_COMPARISON_MODE ComparisonMode = "TOKENS"
+	// like TRAILING_WHITESPACE but letter case is ignored too
+	CASE_INSENSITIVE_COMPARISON_MODE ComparisonMode = "CASE_INSENSITIVE"
+	// token by token, numbers match when they are within the epsilon of
+	// the expected one either absolutely or relatively
+	FLOAT_COMPARISON_MODE ComparisonMode = "FLOAT"
+)
+
+var ComparisonModes = []ComparisonMode{
+	EXACT_COMPARISON_MODE,
+	TRAILING_WHITESPACE_COMPARISON_MODE,
+	TOKENS_COMPARISON_MODE,
+	CASE_INSENSITIVE_COMPARISON_MODE,
+	FLOAT_COMPARISON_MODE,
+}
+
+type TestVisibility string
+
+const (
+	// shown with the problem statement and in results
+	SAMPLE_TEST_VISIBILITY TestVisibility = "SAMPLE"
+	// only admins see its input and output
+	HIDDEN_TEST_VISIBILITY TestVisibility = "HIDDEN"
 )
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+)
+
+// the submitted function goes where the harness template has this
+const HARNESS_SOLUTION_PLACEHOLDER = "{{SOLUTION}}"
+
+// Harness is the driver code of a function problem for one language, it
+// reads the test, calls the submitted function and prints what it returns
+type Harness struct {
+	Language    int    `json:"language_id"`
+	Template    string `json:"template"`
+	StarterCode string `json:"starter_code"`
+}
+
+func HarnessFromDBObject(h *database.ProblemHarness) *Harness {
+	return &Harness{
+		Language:    int(h.Language),
+		Template:    h.Template,
+		StarterCode: h.StarterCode,
+	}
+}
+
+func (h *Harness) Validate() error {
+	if !strings.Contains(h.Template, HARNESS_SOLUTION_PLACEHOLDER) {
+		return fmt.Errorf("harness template must have %s where the solution goes", HARNESS_SOLUTION_PLACEHOLDER)
+	}
+	return nil
+}
+
+// Wrap puts the submitted code into the template
+func (h *Harness) Wrap(code string) string {
+	return strings.Replace(h.Template, HARNESS_SOLUTION_PLACEHOLDER, code, 1)
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+)
+
+// LanguageSettings is how submissions of a language are judged compared to
+// the problem limits, slower languages get more time
+type LanguageSettings struct {
+	Language       int     `json:"language_id"`
+	TimeMultiplier float64 `json:"time_multiplier"`
+	// KB on top of the problem memory limit
+	ExtraMemory     float64 `json:"extra_memory"`
+	CompilerOptions string  `json:"compiler_options"`
+}
+
+// DefaultLanguageSettings judges language with the problem limits as they are
+func DefaultLanguageSettings(language int) *LanguageSettings {
+	return &LanguageSettings{
+		Language:       language,
+		TimeMultiplier: 1,
+	}
+}
+
+func LanguageSettingsFromDBObject(ls *database.LanguageSetting) *LanguageSettings {
+	return &LanguageSettings{
+		Language:        int(ls.Language),
+		TimeMultiplier:  ls.TimeMultiplier,
+		ExtraMemory:     ls.ExtraMemory,
+		CompilerOptions: ls.CompilerOptions,
+	}
+}
+
+func (ls *LanguageSettings) Validate() error {
+	if ls.TimeMultiplier <= 0 {
+		return fmt.Errorf("time multiplier must be positive")
+	}
+	if ls.ExtraMemory < 0 {
+		return fmt.Errorf("extra memory can not be negative")
+	}
+	return nil
+}
+
+// the most judge zero allows by default, it turns away submissions asking
+// for more so the limits of a language stop here
+const (
+	MAX_JUDGE_TIME_LIMIT   = 15.0
+	MAX_JUDGE_MEMORY_LIMIT = 512000.0
+)
+
+func (ls *LanguageSettings) TimeLimit(problemTimeLimit float64) float64 {
+	return min(problemTimeLimit*ls.TimeMultiplier, MAX_JUDGE_TIME_LIMIT)
+}
+
+func (ls *LanguageSettings) MemoryLimit(problemMemoryLimit float64) float64 {
+	return min(problemMemoryLimit+ls.ExtraMemory, MAX_JUDGE_MEMORY_LIMIT)
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Modalessi/nuha-api/internal/database"
@@ -10,29 +11,43 @@ import (
 	"github.com/google/uuid"
 )
 
+const DEFAULT_COMPARISON_EPSILON = 1e-6
+
 type Problem struct {
-	ID          *uuid.UUID
-	Title       string
-	Description string
-	Difficulty  string
-	Tags        []string
-	Testcases   []Testcase
-	Timelimit   float64
-	Memorylimit float64
-	CreatedAt   *time.Time
-	UpdatedAt   *time.Time
+	ID                *uuid.UUID
+	Title             string
+	Description       string
+	Difficulty        string
+	Tags              []string
+	Testcases         []Testcase
+	Timelimit         float64
+	Memorylimit       float64
+	JudgingMode       JudgingMode
+	ScoringMode       ScoringMode
+	ComparisonMode    ComparisonMode
+	ComparisonEpsilon float64
+	ProblemType       ProblemType
+	FunctionSignature string
+	CreatedAt         *time.Time
+	UpdatedAt         *time.Time
 }
 
 func ProblemFromDBObject(p *database.Problem) *Problem {
 	return &Problem{
-		ID:          &p.ID,
-		Title:       p.Title,
-		Difficulty:  p.Difficulty,
-		Tags:        p.Tags,
-		Timelimit:   p.TimeLimit,
-		Memorylimit: p.MemoryLimit,
-		CreatedAt:   &p.CreatedAt,
-		UpdatedAt:   &p.UpdatedAt,
+		ID:                &p.ID,
+		Title:             p.Title,
+		Difficulty:        p.Difficulty,
+		Tags:              p.Tags,
+		Timelimit:         p.TimeLimit,
+		Memorylimit:       p.MemoryLimit,
+		JudgingMode:       JudgingMode(p.JudgingMode),
+		ScoringMode:       ScoringMode(p.ScoringMode),
+		ComparisonMode:    ComparisonMode(p.ComparisonMode),
+		ComparisonEpsilon: p.ComparisonEpsilon,
+		ProblemType:       ProblemType(p.ProblemType),
+		FunctionSignature: p.FunctionSignature,
+		CreatedAt:         &p.CreatedAt,
+		UpdatedAt:         &p.UpdatedAt,
 	}
 }
 
@@ -43,13 +58,18 @@ func CreateNewProblem(title string, description string, difficulty string, tags
 	}
 
 	return &Problem{
-		Title:       title,
-		Description: description,
-		Difficulty:  difficulty,
-		Tags:        []string{},
-		Testcases:   []Testcase{},
-		Timelimit:   1,
-		Memorylimit: 128000,
+		Title:             title,
+		Description:       description,
+		Difficulty:        difficulty,
+		Tags:              []string{},
+		Testcases:         []Testcase{},
+		Timelimit:         1,
+		Memorylimit:       128000,
+		JudgingMode:       ALL_TESTS_JUDGING_MODE,
+		ScoringMode:       SUM_SCORING_MODE,
+		ComparisonMode:    TRAILING_WHITESPACE_COMPARISON_MODE,
+		ComparisonEpsilon: DEFAULT_COMPARISON_EPSILON,
+		ProblemType:       STANDARD_PROBLEM_TYPE,
 	}, nil
 }
 
@@ -82,6 +102,55 @@ func (p *Problem) SetMemoryLimit(memorylimit float64) {
 	p.Memorylimit = memorylimit
 }
 
+func (p *Problem) SetJudgingMode(mode string) error {
+	if JudgingMode(mode) != ALL_TESTS_JUDGING_MODE && JudgingMode(mode) != STOP_ON_FIRST_FAILURE_JUDGING_MODE {
+		return fmt.Errorf("judging mode must be one of these (%s, %s)", ALL_TESTS_JUDGING_MODE, STOP_ON_FIRST_FAILURE_JUDGING_MODE)
+	}
+
+	p.JudgingMode = JudgingMode(mode)
+	return nil
+}
+
+func (p *Problem) SetScoringMode(mode string) error {
+	if ScoringMode(mode) != SUM_SCORING_MODE && ScoringMode(mode) != MIN_SCORING_MODE {
+		return fmt.Errorf("scoring mode must be one of these (%s, %s)", SUM_SCORING_MODE, MIN_SCORING_MODE)
+	}
+
+	p.ScoringMode = ScoringMode(mode)
+	return nil
+}
+
+func (p *Problem) SetComparisonMode(mode string) error {
+	if !slices.Contains(ComparisonModes, ComparisonMode(mode)) {
+		return fmt.Errorf("comparison mode must be one of these %v", ComparisonModes)
+	}
+
+	p.ComparisonMode = ComparisonMode(mode)
+	return nil
+}
+
+func (p *Problem) SetComparisonEpsilon(epsilon float64) error {
+	if epsilon < 0 {
+		return fmt.Errorf("comparison epsilon can not be negative")
+	}
+
+	p.ComparisonEpsilon = epsilon
+	return nil
+}
+
+func (p *Problem) SetProblemType(problemType string) error {
+	if !slices.Contains(ProblemTypes, ProblemType(problemType)) {
+		return fmt.Errorf("problem type must be one of these %v", ProblemTypes)
+	}
+
+	p.ProblemType = ProblemType(problemType)
+	return nil
+}
+
+func (p *Problem) SetFunctionSignature(signature string) {
+	p.FunctionSignature = signature
+}
+
 func (p *Problem) JSON() []byte {
 	data, err := json.Marshal(p)
 	utils.Assert(err, "error converting problem object to json")
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+)
+
+type TestGroup struct {
+	Number int     `json:"number"`
+	Points float64 `json:"points"`
+	// groups that must be fully passed before this one scores anything
+	Dependencies []int `json:"dependencies"`
+}
+
+func TestGroupsFromDBObjects(groups []database.TestGroup) []TestGroup {
+	tgs := make([]TestGroup, len(groups))
+	for i, g := range groups {
+		dependencies := make([]int, len(g.Dependencies))
+		for j, d := range g.Dependencies {
+			dependencies[j] = int(d)
+		}
+
+		tgs[i] = TestGroup{
+			Number:       int(g.Number),
+			Points:       g.Points,
+			Dependencies: dependencies,
+		}
+	}
+	return tgs
+}
+
+// ValidateTestGroups checks the groups of one problem, a group can only depend
+// on groups with a lower number so dependencies never go in circles
+func ValidateTestGroups(groups []TestGroup) error {
+	numbers := make(map[int]bool, len(groups))
+	for _, g := range groups {
+		if g.Number <= 0 {
+			return fmt.Errorf("test group number must be positive, got %d", g.Number)
+		}
+		if numbers[g.Number] {
+			return fmt.Errorf("test group %d is given more than once", g.Number)
+		}
+		if g.Points < 0 {
+			return fmt.Errorf("test group %d has negative points", g.Number)
+		}
+		numbers[g.Number] = true
+	}
+
+	for _, g := range groups {
+		for _, d := range g.Dependencies {
+			if !numbers[d] {
+				return fmt.Errorf("test group %d depends on group %d which does not exist", g.Number, d)
+			}
+			if d >= g.Number {
+				return fmt.Errorf("test group %d can only depend on groups before it, not %d", g.Number, d)
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/Modalessi/nuha-api/internal/database"
 	"github.com/Modalessi/nuha-api/internal/utils"
@@ -10,6 +11,16 @@ import (
 type Testcase struct {
 	Stdin          string `json:"stdin"`
 	ExpectedOutput string `json:"expected_output"`
+	// the test group it is scored in, 0 for none
+	Group int `json:"group,omitempty"`
+	// HIDDEN when empty
+	Visibility TestVisibility `json:"visibility,omitempty"`
+}
+
+// OutputFileName is the name of the output file of test number n, the same
+// one test case zips use
+func OutputFileName(n int) string {
+	return fmt.Sprintf("%d.out", n)
 }
 
 func NewTestCase(stdin string, expectedOutput string) *Testcase {
@@ -24,11 +35,24 @@ func TestCasesFromDBObjects(testcases []database.TestCase) []Testcase {
 	tcs := make([]Testcase, len(testcases))
 	for i, tc := range testcases {
 		tcs[i] = *NewTestCase(tc.Stdin, tc.ExpectedOutput)
+		tcs[i].Group = int(tc.GroupNumber)
+		tcs[i].Visibility = TestVisibility(tc.Visibility)
 	}
 
 	return tcs
 }
 
+func (t *Testcase) GetVisibility() TestVisibility {
+	if t.Visibility == "" {
+		return HIDDEN_TEST_VISIBILITY
+	}
+	return t.Visibility
+}
+
+func (t *Testcase) IsSample() bool {
+	return t.Visibility == SAMPLE_TEST_VISIBILITY
+}
+
 func (t *Testcase) JSON() []byte {
 	data, err := json.Marshal(t)
 	utils.Assert(err, "error converting user object to json")
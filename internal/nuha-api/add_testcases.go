@@ -8,6 +8,8 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Modalessi/nuha-api/internal/models"
 	"github.com/Modalessi/nuha-api/internal/repositories"
@@ -42,6 +44,13 @@ func addTestCases(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 			return err
 		}
 
+		// samples=1,2 marks tests of the zip as samples, the rest are hidden
+		err = markSamples(requestTestcases, r.FormValue("samples"))
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+
 	} else {
 		err = json.NewDecoder(r.Body).Decode(&requestTestcases)
 		if err != nil {
@@ -50,6 +59,19 @@ func addTestCases(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 		}
 	}
 
+	for i, tc := range requestTestcases {
+		if tc.Group < 0 {
+			err := fmt.Errorf("test case %d has a negative group", i+1)
+			respondWithError(w, 400, err)
+			return err
+		}
+		if v := tc.GetVisibility(); v != models.SAMPLE_TEST_VISIBILITY && v != models.HIDDEN_TEST_VISIBILITY {
+			err := fmt.Errorf("test case %d visibility must be SAMPLE or HIDDEN", i+1)
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
 	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
 
 	// check if problem exist
@@ -110,3 +132,18 @@ func getTestCasesFromZipFile(file *multipart.File, size int64) ([]models.Testcas
 
 	return testcases, nil
 }
+
+func markSamples(testcases []models.Testcase, samples string) error {
+	if samples == "" {
+		return nil
+	}
+
+	for _, s := range strings.Split(samples, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 || n > len(testcases) {
+			return fmt.Errorf("sample %q is not a test of the zip", s)
+		}
+		testcases[n-1].Visibility = models.SAMPLE_TEST_VISIBILITY
+	}
+	return nil
+}
@@ -12,12 +12,18 @@ import (
 func createProblem(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
 
 	type createProblemSchema struct {
-		Title       string   `json:"title"`
-		Description string   `json:"description"`
-		Difficulty  string   `json:"difficulty"`
-		Tags        []string `json:"tags"`
-		Timelimit   float64  `json:"timelimit,omitempty"`
-		Memorylimit float64  `json:"memorylimit,omitempty"`
+		Title             string   `json:"title"`
+		Description       string   `json:"description"`
+		Difficulty        string   `json:"difficulty"`
+		Tags              []string `json:"tags"`
+		Timelimit         float64  `json:"timelimit,omitempty"`
+		Memorylimit       float64  `json:"memorylimit,omitempty"`
+		JudgingMode       string   `json:"judging_mode,omitempty"`
+		ScoringMode       string   `json:"scoring_mode,omitempty"`
+		ComparisonMode    string   `json:"comparison_mode,omitempty"`
+		ComparisonEpsilon *float64 `json:"comparison_epsilon,omitempty"`
+		ProblemType       string   `json:"problem_type,omitempty"`
+		FunctionSignature string   `json:"function_signature,omitempty"`
 	}
 
 	defer r.Body.Close()
@@ -44,6 +50,45 @@ func createProblem(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 	if problemData.Memorylimit != 0 {
 		problem.SetMemoryLimit(problem.Memorylimit)
 	}
+	if problemData.JudgingMode != "" {
+		err := problem.SetJudgingMode(problemData.JudgingMode)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+	if problemData.ScoringMode != "" {
+		err := problem.SetScoringMode(problemData.ScoringMode)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	if problemData.ComparisonMode != "" {
+		err := problem.SetComparisonMode(problemData.ComparisonMode)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+	if problemData.ComparisonEpsilon != nil {
+		err := problem.SetComparisonEpsilon(*problemData.ComparisonEpsilon)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	if problemData.ProblemType != "" {
+		err := problem.SetProblemType(problemData.ProblemType)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	problem.SetFunctionSignature(problemData.FunctionSignature)
 
 	// store problem
 	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
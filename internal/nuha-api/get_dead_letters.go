@@ -0,0 +1,103 @@
+package nuha
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal"
+	"github.com/Modalessi/nuha-api/internal/database"
+	submissionsPL "github.com/Modalessi/nuha-api/internal/nuha-api/submissions_pipeline"
+	"github.com/google/uuid"
+)
+
+type deadLetter struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+	Attempts     int32     `json:"attempts"`
+	Error        string    `json:"error"`
+	Response     string    `json:"response"`
+	FailedAt     time.Time `json:"failed_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func deadLetterFromJob(job database.JudgeJob) deadLetter {
+	return deadLetter{
+		SubmissionID: job.SubmissionID,
+		Attempts:     job.Attempts,
+		Error:        job.LastError,
+		Response:     job.LastResponse,
+		FailedAt:     job.UpdatedAt,
+		CreatedAt:    job.CreatedAt,
+	}
+}
+
+func getDeadLetters(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	if r.URL.Query().Get("submission_id") != "" {
+		return getDeadLetter(ns, w, r)
+	}
+
+	pagination := internal.ParsePaginationRequest(r)
+
+	getDeadJobsParams := database.GetDeadJudgeJobsParams{
+		Offset: pagination.GetOffset(),
+		Limit:  pagination.GetLimit(),
+	}
+	jobs, err := ns.DBQueries.GetDeadJudgeJobs(r.Context(), getDeadJobsParams)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	deadLetters := make([]deadLetter, len(jobs))
+	for i, job := range jobs {
+		deadLetters[i] = deadLetterFromJob(job)
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: deadLetters})
+	return nil
+}
+
+func getDeadLetter(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	job, err := deadJobFromQuery(ns, w, r)
+	if err != nil {
+		return err
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: deadLetterFromJob(job)})
+	return nil
+}
+
+// deadJobFromQuery loads the dead job of the submission_id query and responds
+// with the error itself when there is none
+func deadJobFromQuery(ns *NuhaServer, w http.ResponseWriter, r *http.Request) (database.JudgeJob, error) {
+	submissionId := r.URL.Query().Get("submission_id")
+	if submissionId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return database.JudgeJob{}, fmt.Errorf("error, submission_id query was not provided")
+	}
+
+	id, err := uuid.Parse(submissionId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return database.JudgeJob{}, err
+	}
+
+	job, err := ns.DBQueries.GetJudgeJobBySubmissionID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, EntityDoesNotExistError("Judge job"))
+		return job, err
+	}
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return job, err
+	}
+
+	if job.State != submissionsPL.JOB_DEAD {
+		respondWithError(w, 409, JOB_NOT_DEAD_ERROR)
+		return job, fmt.Errorf("judge job of submission %v is %v not dead", id, job.State)
+	}
+
+	return job, nil
+}
@@ -0,0 +1,18 @@
+package nuha
+
+import (
+	"net/http"
+
+	"github.com/Modalessi/nuha-api/internal"
+)
+
+func getLanguages(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	languages, err := ns.JudgeAPI.GetLanguages()
+	if err != nil {
+		respondWithError(w, 502, SERVER_ERROR)
+		return err
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: languages})
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/Modalessi/nuha-api/internal"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
 	"github.com/Modalessi/nuha-api/internal/repositories"
 	"github.com/google/uuid"
 )
@@ -34,24 +36,91 @@ func getProblem(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
 		return err
 	}
 
+	testGroups, err := pr.GetTestGroups(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	checker, err := pr.GetChecker(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	interactor, err := pr.GetInteractor(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	harnesses, err := pr.GetHarnesses(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	samples, err := pr.GetSampleTestCases(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	// the harness templates stay hidden, participants only get the stubs
+	type starter struct {
+		Language    int    `json:"language_id"`
+		Name        string `json:"language"`
+		StarterCode string `json:"starter_code"`
+	}
+	starters := make([]starter, len(harnesses))
+	for i, h := range harnesses {
+		starters[i] = starter{
+			Language:    h.Language,
+			Name:        judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(h.Language)],
+			StarterCode: h.StarterCode,
+		}
+	}
+
 	type responeProblem struct {
-		Id          string   `json:"id"`
-		Title       string   `json:"title"`
-		Difficulty  string   `json:"difficulty"`
-		Discription string   `json:"discription"`
-		Tags        []string `json:"tags"`
-		TimeLimit   float64  `json:"time_limit"`
-		MemoryLimit float64  `json:"memory_limit"`
+		Id                string             `json:"id"`
+		Title             string             `json:"title"`
+		Difficulty        string             `json:"difficulty"`
+		Discription       string             `json:"discription"`
+		Tags              []string           `json:"tags"`
+		TimeLimit         float64            `json:"time_limit"`
+		MemoryLimit       float64            `json:"memory_limit"`
+		JudgingMode       string             `json:"judging_mode"`
+		ScoringMode       string             `json:"scoring_mode"`
+		ComparisonMode    string             `json:"comparison_mode"`
+		ComparisonEpsilon float64            `json:"comparison_epsilon"`
+		TestGroups        []models.TestGroup `json:"test_groups"`
+		Samples           []models.Testcase  `json:"samples"`
+		HasChecker        bool               `json:"has_checker"`
+		ProblemType       string             `json:"problem_type"`
+		HasInteractor     bool               `json:"has_interactor"`
+		FunctionSignature string             `json:"function_signature,omitempty"`
+		Starters          []starter          `json:"starters,omitempty"`
 	}
 
 	response := responeProblem{
-		Id:          problemDB.ID.String(),
-		Title:       problemDB.Title,
-		Difficulty:  problemDB.Difficulty,
-		Discription: problemDescription,
-		Tags:        problemDB.Tags,
-		TimeLimit:   problemDB.TimeLimit,
-		MemoryLimit: problemDB.MemoryLimit,
+		Id:                problemDB.ID.String(),
+		Title:             problemDB.Title,
+		Difficulty:        problemDB.Difficulty,
+		Discription:       problemDescription,
+		Tags:              problemDB.Tags,
+		TimeLimit:         problemDB.TimeLimit,
+		MemoryLimit:       problemDB.MemoryLimit,
+		JudgingMode:       problemDB.JudgingMode,
+		ScoringMode:       problemDB.ScoringMode,
+		ComparisonMode:    problemDB.ComparisonMode,
+		ComparisonEpsilon: problemDB.ComparisonEpsilon,
+		TestGroups:        testGroups,
+		Samples:           samples,
+		HasChecker:        checker != nil,
+		ProblemType:       problemDB.ProblemType,
+		HasInteractor:     interactor != nil,
+		FunctionSignature: problemDB.FunctionSignature,
+		Starters:          starters,
 	}
 
 	respondWithJson(w, 200, &internal.JsonWrapper{Data: response})
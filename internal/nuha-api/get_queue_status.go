@@ -0,0 +1,18 @@
+package nuha
+
+import (
+	"net/http"
+
+	"github.com/Modalessi/nuha-api/internal"
+)
+
+func getQueueStatus(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	queue, err := ns.SubmissionsPL.QueueStatus(r.Context())
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: queue})
+	return nil
+}
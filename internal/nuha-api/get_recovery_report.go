@@ -0,0 +1,19 @@
+package nuha
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Modalessi/nuha-api/internal"
+)
+
+func getRecoveryReport(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	report := ns.SubmissionsPL.LastRecovery()
+	if report == nil {
+		respondWithError(w, 404, EntityDoesNotExistError("Recovery report"))
+		return fmt.Errorf("pending submissions were not recovered on startup")
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: report})
+	return nil
+}
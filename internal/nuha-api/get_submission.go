@@ -9,6 +9,7 @@ import (
 	"github.com/Modalessi/nuha-api/internal"
 	"github.com/Modalessi/nuha-api/internal/database"
 	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
 	"github.com/google/uuid"
 )
 
@@ -48,22 +49,49 @@ func getSubmission(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 		return err
 	}
 
+	// the other source files of multi-file submissions, or the outputs of output only ones
+	files, err := judgeAPI.UnzipFiles(submissionDB.Files)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+	fileContents := make(map[string]string, len(files))
+	for name, content := range files {
+		fileContents[name] = string(content)
+	}
+
+	compileOutput, err := submissionCompileOutput(ns, r, submissionDB)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
 	response := struct {
-		ID        uuid.UUID `json:"id"`
-		ProblemID uuid.UUID `json:"problem_id"`
-		UserID    uuid.UUID `json:"user_id"`
-		Status    string    `json:"status"`
-		Language  string    `json:"language"`
-		Code      string    `json:"code"`
-		CreatedAT string    `json:"created_at"`
+		ID            uuid.UUID         `json:"id"`
+		ProblemID     uuid.UUID         `json:"problem_id"`
+		UserID        uuid.UUID         `json:"user_id"`
+		Status        string            `json:"status"`
+		FailedTest    *int32            `json:"failed_test"`
+		Score         *float64          `json:"score"`
+		Language      string            `json:"language"`
+		Code          string            `json:"code"`
+		EntryPoint    string            `json:"entry_point,omitempty"`
+		Files         map[string]string `json:"files,omitempty"`
+		CompileOutput string            `json:"compile_output,omitempty"`
+		CreatedAT     string            `json:"created_at"`
 	}{
-		ID:        submissionDB.ID,
-		ProblemID: submissionDB.ProblemID,
-		UserID:    submissionDB.UserID,
-		Status:    submissionDB.Status,
-		Language:  judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(submissionDB.Language)],
-		Code:      submissionDB.SourceCode,
-		CreatedAT: submissionDB.CreatedAt.String(),
+		ID:            submissionDB.ID,
+		ProblemID:     submissionDB.ProblemID,
+		UserID:        submissionDB.UserID,
+		Status:        submissionDB.Status,
+		FailedTest:    failedTest(submissionDB),
+		Score:         score(submissionDB),
+		Language:      judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(submissionDB.Language)],
+		Code:          submissionDB.SourceCode,
+		EntryPoint:    submissionDB.EntryPoint,
+		Files:         fileContents,
+		CompileOutput: compileOutput,
+		CreatedAT:     submissionDB.CreatedAt.String(),
 	}
 
 	respondWithJson(w, 200, &internal.JsonWrapper{Data: response})
@@ -261,27 +289,81 @@ func getSubmissions(ns *NuhaServer, w http.ResponseWriter, r *http.Request) erro
 
 func respondWithSubmissoins(w http.ResponseWriter, submissions []database.Submission) {
 	type SubmissionDetails struct {
-		ID        uuid.UUID `json:"id"`
-		ProblemID uuid.UUID `json:"problem_id"`
-		UserID    uuid.UUID `json:"user_id"`
-		Status    string    `json:"status"`
-		Language  string    `json:"language"`
-		Code      string    `json:"code"`
-		CreatedAT string    `json:"created_at"`
+		ID         uuid.UUID `json:"id"`
+		ProblemID  uuid.UUID `json:"problem_id"`
+		UserID     uuid.UUID `json:"user_id"`
+		Status     string    `json:"status"`
+		FailedTest *int32    `json:"failed_test"`
+		Score      *float64  `json:"score"`
+		Language   string    `json:"language"`
+		Code       string    `json:"code"`
+		CreatedAT  string    `json:"created_at"`
 	}
 
 	response := make([]SubmissionDetails, 0)
 	for _, submissionDB := range submissions {
 		response = append(response, SubmissionDetails{
-			ID:        submissionDB.ID,
-			ProblemID: submissionDB.ProblemID,
-			UserID:    submissionDB.UserID,
-			Status:    submissionDB.Status,
-			Language:  judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(submissionDB.Language)],
-			Code:      submissionDB.SourceCode,
-			CreatedAT: submissionDB.CreatedAt.String(),
+			ID:         submissionDB.ID,
+			ProblemID:  submissionDB.ProblemID,
+			UserID:     submissionDB.UserID,
+			Status:     submissionDB.Status,
+			FailedTest: failedTest(submissionDB),
+			Score:      score(submissionDB),
+			Language:   judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(submissionDB.Language)],
+			Code:       submissionDB.SourceCode,
+			CreatedAT:  submissionDB.CreatedAt.String(),
 		})
 	}
 
 	respondWithJson(w, 200, &internal.JsonWrapper{Data: response})
 }
+
+// failedTest is the number of the first test the submission failed, nil
+// when it passed them all or never got that far
+func failedTest(submission database.Submission) *int32 {
+	if !submission.FailedTest.Valid {
+		return nil
+	}
+	return &submission.FailedTest.Int32
+}
+
+// score is nil for problems without test groups
+func score(submission database.Submission) *float64 {
+	if !submission.Score.Valid {
+		return nil
+	}
+	return &submission.Score.Float64
+}
+
+// submissionCompileOutput is why the submission did not compile, empty when
+// it compiled or the user is not its owner or an admin
+func submissionCompileOutput(ns *NuhaServer, r *http.Request, submission database.Submission) (string, error) {
+	if models.SubmissionStatus(submission.Status) != models.COMPILATION_ERROR_SUBMISSION_STATUS {
+		return "", nil
+	}
+
+	userEmail, ok := r.Context().Value(USER_EMAIL_CONTEXT_KEY).(string)
+	if !ok {
+		return "", fmt.Errorf("error getting user email from context")
+	}
+	if userEmail != ns.AdminEmail {
+		user, err := ns.UserRepo.GetUserByEmail(r.Context(), userEmail)
+		if err != nil {
+			return "", err
+		}
+		if user.ID != submission.UserID {
+			return "", nil
+		}
+	}
+
+	// every test compiles the same code, the first one says it all
+	results, err := ns.DBQueries.GetSubmissionResultsBySubmissionID(r.Context(), database.GetSubmissionResultsBySubmissionIDParams{
+		SubmissionID: submission.ID,
+		Offset:       0,
+		Limit:        1,
+	})
+	if err != nil || len(results) == 0 {
+		return "", err
+	}
+	return results[0].CompileOutput, nil
+}
@@ -0,0 +1,167 @@
+package nuha
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Modalessi/nuha-api/internal"
+	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// bytes of the stdin, stdout and expected output of a test that are shown
+const RESULT_PREVIEW_SIZE = 1024
+
+type lineDifference struct {
+	Line     int    `json:"line"`
+	Output   string `json:"output"`
+	Expected string `json:"expected"`
+}
+
+type testResult struct {
+	Test       int     `json:"test"`
+	Status     string  `json:"status"`
+	Time       string  `json:"time"`
+	Memory     float64 `json:"memory"`
+	Visibility string  `json:"visibility"`
+	// only for sample tests, admins see all of them
+	ExitCode        *int32          `json:"exit_code,omitempty"`
+	ExitSignal      int32           `json:"exit_signal,omitempty"`
+	Message         string          `json:"message,omitempty"`
+	Stdin           *string         `json:"stdin,omitempty"`
+	Stderr          *string         `json:"stderr,omitempty"`
+	Stdout          *string         `json:"stdout,omitempty"`
+	ExpectedOutput  *string         `json:"expected_output,omitempty"`
+	Truncated       bool            `json:"truncated,omitempty"`
+	FirstDifference *lineDifference `json:"first_difference,omitempty"`
+}
+
+// getSubmissionResults lists the result of every test the submission ran on,
+// only to the user who submitted it and admins
+func getSubmissionResults(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	submissionId := r.URL.Query().Get("submission_id")
+	if submissionId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, submission_id query was not provided")
+	}
+
+	id, err := uuid.Parse(submissionId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	userEmail, ok := r.Context().Value(USER_EMAIL_CONTEXT_KEY).(string)
+	if !ok {
+		respondWithError(w, 500, SERVER_ERROR)
+		return fmt.Errorf("error getting user email from context")
+	}
+	isAdmin := userEmail == ns.AdminEmail
+
+	submissionDB, err := ns.DBQueries.GetSubmissionByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, EntityDoesNotExistError("SUBMISSION"))
+		return err
+	}
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	// submissions of other users do not exist as far as anyone can tell
+	if !isAdmin {
+		user, err := ns.UserRepo.GetUserByEmail(r.Context(), userEmail)
+		if err != nil {
+			respondWithError(w, 404, EntityDoesNotExistError("USER"))
+			return err
+		}
+		if user.ID != submissionDB.UserID {
+			respondWithError(w, 404, EntityDoesNotExistError("SUBMISSION"))
+			return fmt.Errorf("user %v asked for the results of submission %v of another user", user.ID, id)
+		}
+	}
+
+	pagination := internal.ParsePaginationRequest(r)
+	resultsDB, err := ns.DBQueries.GetSubmissionResultsBySubmissionID(r.Context(), database.GetSubmissionResultsBySubmissionIDParams{
+		SubmissionID: id,
+		Offset:       pagination.GetOffset(),
+		Limit:        pagination.GetLimit(),
+	})
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	results := make([]testResult, len(resultsDB))
+	for i, result := range resultsDB {
+		results[i] = testResult{
+			Test:   int(result.TestNumber),
+			Status: judgeAPI.JudgeSubmissionStatusDescription[judgeAPI.JudgeSubmissionStatusID(result.StatusID)],
+			Time:   result.TimeUsed,
+			Memory: result.MemoryUsed,
+			// the visibility the test had when it was judged, the test at
+			// this number now can be another one
+			Visibility: result.Visibility,
+		}
+		// outputs, exit codes and checker or interactor comments can give hidden tests away
+		if !isAdmin && models.TestVisibility(result.Visibility) != models.SAMPLE_TEST_VISIBILITY {
+			continue
+		}
+
+		results[i].ExitCode = &result.ExitCode
+		results[i].ExitSignal = result.ExitSignal
+		results[i].Message = result.Message
+		stdin, stdinTruncated := truncatePreview(result.Stdin)
+		stdout, stdoutTruncated := truncatePreview(result.Stdout)
+		stderr, stderrTruncated := truncatePreview(result.Stderr)
+		expected, expectedTruncated := truncatePreview(result.ExpectedOutput)
+		results[i].Stdin = &stdin
+		results[i].Stdout = &stdout
+		results[i].Stderr = &stderr
+		results[i].ExpectedOutput = &expected
+		results[i].Truncated = stdinTruncated || stdoutTruncated || stderrTruncated || expectedTruncated
+
+		if judgeAPI.JudgeSubmissionStatusID(result.StatusID) == judgeAPI.WRONG_ANSWER_STATUS {
+			results[i].FirstDifference = firstDifference(result.Stdout, result.ExpectedOutput)
+		}
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: results})
+	return nil
+}
+
+func truncatePreview(s string) (string, bool) {
+	if len(s) <= RESULT_PREVIEW_SIZE {
+		return s, false
+	}
+	return strings.ToValidUTF8(s[:RESULT_PREVIEW_SIZE], ""), true
+}
+
+// firstDifference is the first line where output and expected differ,
+// trailing whitespace ignored. nil when the lines are the same, like when
+// the checker or a looser comparison mode judged the output
+func firstDifference(output string, expected string) *lineDifference {
+	outputLines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	expectedLines := strings.Split(strings.TrimRight(expected, "\n"), "\n")
+
+	for i := 0; i < len(outputLines) || i < len(expectedLines); i++ {
+		outputLine, expectedLine := "", ""
+		if i < len(outputLines) {
+			outputLine = strings.TrimRight(outputLines[i], " \t\r")
+		}
+		if i < len(expectedLines) {
+			expectedLine = strings.TrimRight(expectedLines[i], " \t\r")
+		}
+		if outputLine != expectedLine {
+			outputLine, _ = truncatePreview(outputLine)
+			expectedLine, _ = truncatePreview(expectedLine)
+			return &lineDifference{Line: i + 1, Output: outputLine, Expected: expectedLine}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package nuha
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+)
+
+func judgeCallbackURL(baseURL string, secret string) string {
+	query := url.Values{}
+	query.Add("key", secret)
+	return strings.TrimSuffix(baseURL, "/") + "/judge/callback?" + query.Encode()
+}
+
+// judgeCallback receives the PUT judge zero sends for every finished token
+func judgeCallback(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	secret := ns.Config.JudgeCallbackSecret
+	key := r.URL.Query().Get("key")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(key), []byte(secret)) != 1 {
+		respondWithError(w, 403, NOT_AUTHORIZED_ERROR)
+		return fmt.Errorf("judge callback with invalid key")
+	}
+
+	defer r.Body.Close()
+
+	result := judgeAPI.Submission{}
+	err := json.NewDecoder(r.Body).Decode(&result)
+	if err != nil {
+		respondWithError(w, 400, INVALID_JSON_ERROR)
+		return err
+	}
+	err = result.DecodeCallback()
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	err = ns.SubmissionsPL.HandleJudgeCallback(result)
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	respondWithSuccess(w, 200, "result received")
+	return nil
+}
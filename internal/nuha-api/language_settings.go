@@ -0,0 +1,79 @@
+package nuha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Modalessi/nuha-api/internal"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+)
+
+// getLanguageSettings lists the languages that are not judged with the
+// problem limits as they are
+func getLanguageSettings(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	lr := repositories.NewLanguageRepository(ns.DB, ns.DBQueries, r.Context())
+	settings, err := lr.GetAllSettings()
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: settings})
+	return nil
+}
+
+// setLanguageSettings sets the time multiplier, extra memory and compiler
+// options of a language, they apply to submissions judged from now on
+func setLanguageSettings(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	settings := models.LanguageSettings{TimeMultiplier: 1}
+	err := json.NewDecoder(r.Body).Decode(&settings)
+	if err != nil {
+		respondWithError(w, 400, INVALID_JSON_ERROR)
+		return err
+	}
+
+	if _, ok := judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(settings.Language)]; !ok {
+		respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+		return fmt.Errorf("language %d is not supported", settings.Language)
+	}
+	err = settings.Validate()
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	lr := repositories.NewLanguageRepository(ns.DB, ns.DBQueries, r.Context())
+	err = lr.SetSettings(&settings)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("settings of %s have been set", judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(settings.Language)]))
+	return nil
+}
+
+// deleteLanguageSettings judges a language with the problem limits again
+func deleteLanguageSettings(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	language, err := strconv.Atoi(r.URL.Query().Get("language_id"))
+	if err != nil {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, language_id query was not provided: %w", err)
+	}
+
+	lr := repositories.NewLanguageRepository(ns.DB, ns.DBQueries, r.Context())
+	err = lr.DeleteSettings(language)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("settings of language %d have been removed", language))
+	return nil
+}
@@ -2,6 +2,7 @@ package nuha
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
 	"time"
 
@@ -16,7 +17,7 @@ import (
 type NuhaServer struct {
 	Server        http.Handler
 	serverMux     *http.ServeMux
-	JudgeAPI      *judgeAPI.JudgeAPI
+	JudgeAPI      judgeAPI.Judge
 	SubmissionsPL *submissionsPL.SubmissionsPipeline
 	DB            *sql.DB
 	DBQueries     *database.Queries
@@ -24,6 +25,24 @@ type NuhaServer struct {
 	UserRepo      *repositories.UserRespository
 	JWTSecret     string
 	AdminEmail    string
+	Config        ServerConfig
+
+	runs       *runStore
+	runLimiter *rateLimiter
+}
+
+type ServerConfig struct {
+	JWTSecret  string
+	AdminEmail string
+
+	// public url judge zero can reach this server on, callbacks are
+	// disabled when it is empty and the pipeline polls instead
+	JudgeCallbackBaseURL string
+	JudgeCallbackSecret  string
+
+	// runs of code on custom input every user can make in a minute,
+	// DEFAULT_RUNS_PER_MINUTE when zero
+	RunsPerMinute int
 }
 
 // this should be better
@@ -49,19 +68,30 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func NewServer(ja *judgeAPI.JudgeAPI, db *sql.DB, dbQuereis *database.Queries, jwtSecret string, adminEmail string) *NuhaServer {
+func NewServer(ja judgeAPI.Judge, db *sql.DB, dbQuereis *database.Queries, config ServerConfig) *NuhaServer {
 	serverMux := http.NewServeMux()
 
-	submissionsPipeline := submissionsPL.NewSubmissionPipeline(ja, db, dbQuereis)
+	pipelineConfig := submissionsPL.DefaultPipelineConfig()
+	if config.JudgeCallbackBaseURL != "" {
+		pipelineConfig.CallbackURL = judgeCallbackURL(config.JudgeCallbackBaseURL, config.JudgeCallbackSecret)
+	}
+
+	submissionsStore := submissionsPL.NewDBStore(db, dbQuereis)
+	submissionsPipeline := submissionsPL.NewSubmissionPipeline(ja, submissionsStore, pipelineConfig)
 
 	authConfig := auth.AuthServiceConfig{
-		JWTSecretKey:              jwtSecret,
+		JWTSecretKey:              config.JWTSecret,
 		TokensExpirationsDuration: (time.Hour * 24),
 	}
 
 	authService := auth.NewAuthService(db, dbQuereis, &email.EmailService{}, authConfig)
 	userRepo := repositories.NewUserRespository(db, dbQuereis)
 
+	runsPerMinute := config.RunsPerMinute
+	if runsPerMinute <= 0 {
+		runsPerMinute = DEFAULT_RUNS_PER_MINUTE
+	}
+
 	ns := NuhaServer{
 		serverMux:     serverMux,
 		JudgeAPI:      ja,
@@ -70,13 +100,17 @@ func NewServer(ja *judgeAPI.JudgeAPI, db *sql.DB, dbQuereis *database.Queries, j
 		DBQueries:     dbQuereis,
 		Auth:          authService,
 		UserRepo:      userRepo,
-		JWTSecret:     jwtSecret,
-		AdminEmail:    adminEmail,
+		JWTSecret:     config.JWTSecret,
+		AdminEmail:    config.AdminEmail,
+		Config:        config,
+		runs:          newRunStore(),
+		runLimiter:    newRateLimiter(runsPerMinute, time.Minute),
 	}
 
 	corsHandler := CORSMiddleware(serverMux)
 
 	serverMux.HandleFunc("GET /healthz", checkHealth)
+	serverMux.HandleFunc("GET /languages", withServer(&ns, getLanguages))
 
 	serverMux.HandleFunc("POST /login", withServer(&ns, login))
 	serverMux.HandleFunc("POST /logout", authorized(withServer(&ns, logout), ns.Auth))
@@ -86,15 +120,44 @@ func NewServer(ja *judgeAPI.JudgeAPI, db *sql.DB, dbQuereis *database.Queries, j
 	serverMux.HandleFunc("GET /protected", authorized(withServer(&ns, protected), ns.Auth))
 
 	serverMux.HandleFunc("POST /submit", authorized(withServer(&ns, submitSolution), ns.Auth))
+	serverMux.HandleFunc("POST /submit/output", authorized(withServer(&ns, submitOutput), ns.Auth))
 	serverMux.HandleFunc("GET /submit", authorized(withServer(&ns, getSubmission), ns.Auth))
+	serverMux.HandleFunc("GET /submit/queue", withServer(&ns, getQueueStatus))
+	serverMux.HandleFunc("GET /submit/results", authorized(withServer(&ns, getSubmissionResults), ns.Auth))
 
-	serverMux.HandleFunc("POST /problem", authorized(adminOnly(withServer(&ns, createProblem), adminEmail), ns.Auth))
+	serverMux.HandleFunc("POST /run", authorized(withServer(&ns, runCode), ns.Auth))
+	serverMux.HandleFunc("GET /run", authorized(withServer(&ns, getRun), ns.Auth))
+
+	serverMux.HandleFunc("POST /problem", authorized(adminOnly(withServer(&ns, createProblem), ns.AdminEmail), ns.Auth))
 	serverMux.HandleFunc("GET /problem", withServer(&ns, getProblem))
 	serverMux.HandleFunc("DELETE /problem", authorized(adminOnly(withServer(&ns, deleteProblem), ns.AdminEmail), ns.Auth))
 	serverMux.HandleFunc("PUT /problem", authorized(adminOnly(withServer(&ns, updateProblem), ns.AdminEmail), ns.Auth))
 
-	serverMux.HandleFunc("POST /testcase", authorized(adminOnly(withServer(&ns, addTestCases), adminEmail), ns.Auth))
-
+	serverMux.HandleFunc("PUT /judge/callback", withServer(&ns, judgeCallback))
+
+	serverMux.HandleFunc("GET /admin/recovery", authorized(adminOnly(withServer(&ns, getRecoveryReport), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("GET /admin/deadletters", authorized(adminOnly(withServer(&ns, getDeadLetters), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("POST /admin/deadletters/retry", authorized(adminOnly(withServer(&ns, retryDeadLetter), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("POST /admin/deadletters/fail", authorized(adminOnly(withServer(&ns, failDeadLetter), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("GET /admin/languages", authorized(adminOnly(withServer(&ns, getLanguageSettings), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("PUT /admin/languages", authorized(adminOnly(withServer(&ns, setLanguageSettings), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("DELETE /admin/languages", authorized(adminOnly(withServer(&ns, deleteLanguageSettings), ns.AdminEmail), ns.Auth))
+
+	serverMux.HandleFunc("POST /testcase", authorized(adminOnly(withServer(&ns, addTestCases), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("PUT /testgroup", authorized(adminOnly(withServer(&ns, setTestGroups), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("PUT /checker", authorized(adminOnly(withServer(&ns, setChecker), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("DELETE /checker", authorized(adminOnly(withServer(&ns, deleteChecker), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("PUT /interactor", authorized(adminOnly(withServer(&ns, setInteractor), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("DELETE /interactor", authorized(adminOnly(withServer(&ns, deleteInteractor), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("PUT /harness", authorized(adminOnly(withServer(&ns, setHarness), ns.AdminEmail), ns.Auth))
+	serverMux.HandleFunc("DELETE /harness", authorized(adminOnly(withServer(&ns, deleteHarness), ns.AdminEmail), ns.Auth))
+
+	report, err := ns.SubmissionsPL.Recover()
+	if err != nil {
+		log.Printf("error recovering pending submissions: %v", err)
+	} else {
+		log.Printf("recovered %d pending submissions, %d will be rejudged", report.Recovered, report.Rejudged)
+	}
 	ns.SubmissionsPL.Start()
 
 	ns.Server = corsHandler
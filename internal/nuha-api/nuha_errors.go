@@ -26,6 +26,10 @@ var (
 	NOT_AUTHORIZED_ERROR       = NuhaError{Code: 403, Message: "you are not authorized to do this operation"}
 	INVALID_TOKEN_ERROR        = NuhaError{Code: 401, Message: "invalid token, please check"}
 	INVALID_ID_ERROR           = NuhaError{Code: 400, Message: "Invalid id was given"}
+	QUEUE_FULL_ERROR           = NuhaError{Code: 503, Message: "the judge is busy right now, please submit again later"}
+	JOB_NOT_DEAD_ERROR         = NuhaError{Code: 409, Message: "this submission is not in the dead letters"}
+	INVALID_LANGUAGE_ERROR     = NuhaError{Code: 400, Message: "this language is not supported"}
+	RUN_RATE_LIMIT_ERROR       = NuhaError{Code: 429, Message: "too many runs, please wait a bit before running again"}
 )
 
 func EntityDoesNotExistError(enitity string) NuhaError {
@@ -0,0 +1,21 @@
+package nuha
+
+import (
+	"net/http"
+)
+
+func retryDeadLetter(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	job, err := deadJobFromQuery(ns, w, r)
+	if err != nil {
+		return err
+	}
+
+	err = ns.SubmissionsPL.RetryDeadJob(r.Context(), job.SubmissionID)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, "submission was sent back to the judge queue")
+	return nil
+}
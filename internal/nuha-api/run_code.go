@@ -0,0 +1,319 @@
+package nuha
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+type runResult struct {
+	Stdin          string  `json:"stdin"`
+	Stdout         string  `json:"stdout"`
+	Stderr         string  `json:"stderr"`
+	CompileOutput  string  `json:"compile_output"`
+	ExpectedOutput *string `json:"expected_output,omitempty"`
+	Status         string  `json:"status"`
+	Time           string  `json:"time"`
+	Memory         float64 `json:"memory"`
+}
+
+type runResponse struct {
+	RunID   uuid.UUID   `json:"run_id"`
+	Done    bool        `json:"done"`
+	Results []runResult `json:"results,omitempty"`
+}
+
+// runCode runs code on the stdin in the body, or on the sample tests of
+// problem_id when there is none, without making a submission. it waits a
+// bit for the judge and gives back a run id for GET /run if it is slower
+func runCode(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, MAX_UPLOAD_SIZE)
+
+	runData, err := readSubmission(r)
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	language := judgeAPI.JudgeLanguage(runData.Language)
+	if _, ok := judgeAPI.JudgeLanguageDescription[language]; !ok || (runData.EntryPoint != "" && !judgeAPI.SupportsScripts(language)) {
+		respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+		return fmt.Errorf("language %d can not be run", runData.Language)
+	}
+
+	code := runData.Code
+	timeLimit := 1.0
+	memoryLimit := 128000.0
+	testcases := []models.Testcase{}
+	if runData.Stdin != nil {
+		testcases = append(testcases, *models.NewTestCase(*runData.Stdin, ""))
+	}
+
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId != "" {
+		id, err := uuid.Parse(problemId)
+		if err != nil {
+			respondWithError(w, 400, INVALID_ID_ERROR)
+			return err
+		}
+
+		pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+		problem, err := pr.GetProblemInfo(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 404, EntityDoesNotExistError("Problem"))
+			return err
+		}
+		if err != nil {
+			respondWithError(w, 500, SERVER_ERROR)
+			return err
+		}
+
+		problemType := models.ProblemType(problem.ProblemType)
+		if problemType == models.INTERACTIVE_PROBLEM_TYPE || problemType == models.OUTPUT_ONLY_PROBLEM_TYPE {
+			err := fmt.Errorf("code of %s problems can not be run on custom input", problemType)
+			respondWithError(w, 400, err)
+			return err
+		}
+
+		if problemType == models.FUNCTION_PROBLEM_TYPE {
+			harness, err := pr.GetHarness(id, runData.Language)
+			if err != nil {
+				respondWithError(w, 500, SERVER_ERROR)
+				return err
+			}
+			if harness == nil {
+				respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+				return fmt.Errorf("problem %s has no harness for language %d", problemId, runData.Language)
+			}
+			code = harness.Wrap(code)
+		}
+
+		timeLimit = problem.TimeLimit
+		memoryLimit = problem.MemoryLimit
+
+		if runData.Stdin == nil {
+			testcases, err = pr.GetSampleTestCases(id)
+			if err != nil {
+				respondWithError(w, 500, SERVER_ERROR)
+				return err
+			}
+		}
+	}
+
+	if len(testcases) == 0 {
+		err := fmt.Errorf("nothing to run on, give a stdin or a problem with sample tests")
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	userEmail, ok := r.Context().Value(USER_EMAIL_CONTEXT_KEY).(string)
+	if !ok {
+		respondWithError(w, 500, SERVER_ERROR)
+		return fmt.Errorf("error getting user email from context")
+	}
+
+	user, err := ns.UserRepo.GetUserByEmail(r.Context(), userEmail)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("USER"))
+		return err
+	}
+
+	queue, err := ns.SubmissionsPL.QueueStatus(r.Context())
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+	if queue.Full() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(queue.RetryAfter.Seconds())))
+		respondWithError(w, 503, QUEUE_FULL_ERROR)
+		return fmt.Errorf("submission queue is full with %d jobs, run turned away", queue.Depth)
+	}
+
+	// only runs that reach the judge count
+	allowed, retryAfter := ns.runLimiter.allow(user.ID)
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		respondWithError(w, 429, RUN_RATE_LIMIT_ERROR)
+		return fmt.Errorf("user %v is over the run rate limit", user.ID)
+	}
+
+	lr := repositories.NewLanguageRepository(ns.DB, ns.DBQueries, r.Context())
+	settings, err := lr.GetSettings(runData.Language)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	program := judgeAPI.Program{Language: language, Code: code, Files: runData.Files, Entry: runData.EntryPoint}
+	submission, err := judgeAPI.NewProgramSubmission(program)
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+	// runs are judged like submissions of the language would be
+	submission.SetCPUTimeLimit(settings.TimeLimit(timeLimit))
+	submission.SetMemoryLimit(settings.MemoryLimit(memoryLimit))
+	if settings.CompilerOptions != "" && submission.LanguageID == runData.Language {
+		submission.SetCompilerOptions(settings.CompilerOptions)
+	}
+	batch := submission.GenerateBatchFromTestCases(testcases...)
+
+	// the run is only shown, nothing is judged
+	expected := make([]string, len(testcases))
+	for i := range *batch {
+		expected[i] = testcases[i].ExpectedOutput
+		(*batch)[i].SetExpectedOutput("")
+	}
+	if runData.Stdin != nil {
+		expected = nil
+	}
+
+	tokens, err := ns.postRun(batch)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return fmt.Errorf("error posting run: %w", err)
+	}
+
+	userRun := &run{userID: user.ID, tokens: tokens, expected: expected}
+	runID := ns.runs.add(userRun)
+
+	response := runResponse{RunID: runID}
+	deadline := time.Now().Add(RUN_WAIT)
+	interval := RUN_POLL_INTERVAL
+	for {
+		response.Results, response.Done, err = ns.runResults(userRun)
+		if err != nil {
+			respondWithError(w, 500, SERVER_ERROR)
+			return err
+		}
+		if response.Done || time.Now().After(deadline) {
+			break
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+		interval = min(interval*2, RUN_MAX_POLL_INTERVAL)
+	}
+
+	status := 200
+	if !response.Done {
+		status = 202
+	}
+	respondWithJson(w, status, &internal.JsonWrapper{Data: response})
+	return nil
+}
+
+func getRun(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	runId := r.URL.Query().Get("run_id")
+	if runId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, run_id query was not provided")
+	}
+
+	id, err := uuid.Parse(runId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	userEmail, ok := r.Context().Value(USER_EMAIL_CONTEXT_KEY).(string)
+	if !ok {
+		respondWithError(w, 500, SERVER_ERROR)
+		return fmt.Errorf("error getting user email from context")
+	}
+
+	user, err := ns.UserRepo.GetUserByEmail(r.Context(), userEmail)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("USER"))
+		return err
+	}
+
+	// runs of other users do not exist as far as anyone can tell
+	userRun := ns.runs.get(id)
+	if userRun == nil || userRun.userID != user.ID {
+		respondWithError(w, 404, EntityDoesNotExistError("RUN"))
+		return fmt.Errorf("run %s does not exist or expired", runId)
+	}
+
+	response := runResponse{RunID: id}
+	response.Results, response.Done, err = ns.runResults(userRun)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithJson(w, 200, &internal.JsonWrapper{Data: response})
+	return nil
+}
+
+// runResults asks the judge for the results of userRun, done is false while
+// any of them is still queued or running. finished runs and runs polled less
+// than RUN_POLL_INTERVAL ago get the results the judge gave last
+// postRun sends the batch in chunks judge zero accepts, problems can have
+// more samples than fit in one
+func (ns *NuhaServer) postRun(batch *judgeAPI.SubmissionBatch) ([]string, error) {
+	tokens := make([]string, 0, len(*batch))
+	for _, chunk := range batch.Chunks(judgeAPI.MAX_BATCH_SIZE) {
+		chunkTokens, err := ns.JudgeAPI.PostBatchSubmission(chunk)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, chunkTokens...)
+	}
+	return tokens, nil
+}
+
+func (ns *NuhaServer) runResults(userRun *run) (results []runResult, done bool, err error) {
+	userRun.mu.Lock()
+	defer userRun.mu.Unlock()
+
+	if userRun.done || time.Since(userRun.polledAt) < RUN_POLL_INTERVAL {
+		return userRun.results, userRun.done, nil
+	}
+
+	submissions := make([]judgeAPI.Submission, 0, len(userRun.tokens))
+	for start := 0; start < len(userRun.tokens); start += judgeAPI.MAX_BATCH_SIZE {
+		chunk, err := ns.JudgeAPI.GetBatchSubmissionsResult(userRun.tokens[start:min(start+judgeAPI.MAX_BATCH_SIZE, len(userRun.tokens))])
+		if err != nil {
+			return nil, false, fmt.Errorf("error getting run results: %w", err)
+		}
+		submissions = append(submissions, chunk...)
+	}
+
+	done = true
+	results = make([]runResult, len(submissions))
+	for i, s := range submissions {
+		if s.Status.ID == judgeAPI.IN_QUEUE_STATUS || s.Status.ID == judgeAPI.PROCESSING_STATUS {
+			done = false
+		}
+
+		results[i] = runResult{
+			Stdin:         s.Stdin,
+			Stdout:        s.Stdout,
+			Stderr:        s.Stderr,
+			CompileOutput: s.CompileOutput,
+			Status:        s.Status.Description,
+			Time:          s.Time,
+			Memory:        s.Memory,
+		}
+		if i < len(userRun.expected) {
+			results[i].ExpectedOutput = &userRun.expected[i]
+		}
+	}
+
+	userRun.results, userRun.done, userRun.polledAt = results, done, time.Now()
+	return results, done, nil
+}
@@ -0,0 +1,110 @@
+package nuha
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// runs are forgotten after this, their results are fetched right away
+	RUN_TTL = 10 * time.Minute
+	// how long POST /run waits for the judge before it gives a run id back
+	RUN_WAIT = 5 * time.Second
+	// the judge is asked about a run at most once per RUN_POLL_INTERVAL, GET
+	// /run gets the last results in between. POST /run waits longer each time
+	RUN_POLL_INTERVAL       = 500 * time.Millisecond
+	RUN_MAX_POLL_INTERVAL   = 2 * time.Second
+	DEFAULT_RUNS_PER_MINUTE = 10
+)
+
+// run is code ran on custom input, it is not a submission and only lives
+// in memory until it expires
+type run struct {
+	userID    uuid.UUID
+	tokens    []string
+	expected  []string // expected outputs when the run is on sample tests
+	expiresAt time.Time
+
+	// the last results the judge gave, guarded by mu
+	mu       sync.Mutex
+	results  []runResult
+	done     bool
+	polledAt time.Time
+}
+
+type runStore struct {
+	mu   sync.Mutex
+	runs map[uuid.UUID]*run
+}
+
+func newRunStore() *runStore {
+	return &runStore{runs: make(map[uuid.UUID]*run)}
+}
+
+func (rs *runStore) add(r *run) uuid.UUID {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	for id, old := range rs.runs {
+		if now.After(old.expiresAt) {
+			delete(rs.runs, id)
+		}
+	}
+
+	id := uuid.New()
+	r.expiresAt = now.Add(RUN_TTL)
+	rs.runs[id] = r
+	return id
+}
+
+// get returns nil for runs that do not exist or expired
+func (rs *runStore) get(id uuid.UUID) *run {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	r, ok := rs.runs[id]
+	if !ok || time.Now().After(r.expiresAt) {
+		return nil
+	}
+	return r
+}
+
+// rateLimiter allows every user limit actions in any window long period
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	recent map[uuid.UUID][]time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		recent: make(map[uuid.UUID][]time.Time),
+	}
+}
+
+// allow records an action of userID, when the user is over the limit it
+// is not recorded and retryAfter is how long until it would be allowed
+func (rl *rateLimiter) allow(userID uuid.UUID) (ok bool, retryAfter time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	recent := rl.recent[userID]
+	for len(recent) > 0 && now.Sub(recent[0]) >= rl.window {
+		recent = recent[1:]
+	}
+
+	if len(recent) >= rl.limit {
+		rl.recent[userID] = recent
+		return false, rl.window - now.Sub(recent[0])
+	}
+
+	rl.recent[userID] = append(recent, now)
+	return true, 0
+}
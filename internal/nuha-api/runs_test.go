@@ -0,0 +1,86 @@
+package nuha
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI/judge0test"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestRateLimiter(t *testing.T) {
+	window := 50 * time.Millisecond
+	rl := newRateLimiter(2, window)
+	user, other := uuid.New(), uuid.New()
+
+	for i := range 2 {
+		if ok, _ := rl.allow(user); !ok {
+			t.Fatalf("run %d was not allowed", i+1)
+		}
+	}
+
+	ok, retryAfter := rl.allow(user)
+	if ok || retryAfter <= 0 || retryAfter > window {
+		t.Fatalf("got allowed %v retry after %v, wanted a wait of at most %v", ok, retryAfter, window)
+	}
+	if ok, _ := rl.allow(other); !ok {
+		t.Fatalf("another user was limited too")
+	}
+
+	time.Sleep(window)
+	if ok, _ := rl.allow(user); !ok {
+		t.Fatalf("run was not allowed once the window passed")
+	}
+}
+
+func TestRunStoreExpiry(t *testing.T) {
+	rs := newRunStore()
+	userID := uuid.New()
+
+	id := rs.add(&run{userID: userID})
+	if r := rs.get(id); r == nil || r.userID != userID {
+		t.Fatalf("got run %v, wanted the one just added", r)
+	}
+	if r := rs.get(uuid.New()); r != nil {
+		t.Fatalf("got a run for an unknown id")
+	}
+
+	rs.runs[id].expiresAt = time.Now().Add(-time.Second)
+	if r := rs.get(id); r != nil {
+		t.Fatalf("got an expired run")
+	}
+
+	// adding a run purges the expired ones
+	rs.add(&run{userID: userID})
+	if _, ok := rs.runs[id]; ok || len(rs.runs) != 1 {
+		t.Fatalf("expired run was kept, store has %d runs", len(rs.runs))
+	}
+}
+
+func TestRunManySamples(t *testing.T) {
+	server := judge0test.NewServer()
+	t.Cleanup(server.Close)
+	ns := &NuhaServer{JudgeAPI: server.Judge()}
+
+	samples := make([]models.Testcase, judgeAPI.MAX_BATCH_SIZE+5)
+	for i := range samples {
+		samples[i] = *models.NewTestCase(fmt.Sprint(i), fmt.Sprint(i))
+	}
+	batch := judgeAPI.NewSubmission("print(input())", judgeAPI.PYTHON_3).GenerateBatchFromTestCases(samples...)
+
+	tokens, err := ns.postRun(batch)
+	if err != nil || len(tokens) != len(samples) {
+		t.Fatalf("got %d tokens and error %v, wanted %d tokens", len(tokens), err, len(samples))
+	}
+
+	results, done, err := ns.runResults(&run{tokens: tokens})
+	if err != nil || !done || len(results) != len(samples) {
+		t.Fatalf("got %d results done %v and error %v", len(results), done, err)
+	}
+	if last := results[len(results)-1]; last.Stdout != fmt.Sprint(len(samples)-1) {
+		t.Fatalf("got stdout %q for the last sample, results are out of order", last.Stdout)
+	}
+}
@@ -0,0 +1,154 @@
+package nuha
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// setChecker takes a testlib style checker for a problem, it is run with
+// input.txt output.txt answer.txt and its exit code decides the verdict.
+// it comes as json or as a checker_file upload with language_id, where any
+// checker_files (testlib.h for one) are put next to it
+func setChecker(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	defer r.Body.Close()
+
+	checker, err := readJuryProgram(r, "checker")
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+
+	_, err = pr.GetProblemInfo(id)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("Problem"))
+		return err
+	}
+
+	err = pr.SetChecker(id, int(checker.Language), checker.Code, checker.Files)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s is now judged by its checker", problemId))
+	return nil
+}
+
+// deleteChecker goes back to comparing with the expected output
+func deleteChecker(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+	err = pr.DeleteChecker(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s checker has been removed", problemId))
+	return nil
+}
+
+// readJuryProgram reads a program like a checker or an interactor, it comes as
+// json or as a <name>_file upload with language_id, where any <name>_files are
+// put next to it
+func readJuryProgram(r *http.Request, name string) (judgeAPI.Program, error) {
+	type programSchema struct {
+		Language   int               `json:"language_id"`
+		SourceCode string            `json:"source_code"`
+		Files      map[string]string `json:"files,omitempty"`
+	}
+
+	programData := programSchema{}
+	files := map[string][]byte{}
+
+	file, _, err := r.FormFile(name + "_file")
+	if err == nil {
+		source, err := io.ReadAll(file)
+		if err != nil {
+			return judgeAPI.Program{}, err
+		}
+		programData.SourceCode = string(source)
+
+		programData.Language, err = strconv.Atoi(r.FormValue("language_id"))
+		if err != nil {
+			return judgeAPI.Program{}, INVALID_LANGUAGE_ERROR
+		}
+
+		files, err = readUploadedFiles(r.MultipartForm.File[name+"_files"])
+		if err != nil {
+			return judgeAPI.Program{}, err
+		}
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&programData)
+		if err != nil {
+			return judgeAPI.Program{}, INVALID_JSON_ERROR
+		}
+
+		for fileName, content := range programData.Files {
+			files[fileName] = []byte(content)
+		}
+	}
+
+	if _, ok := judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(programData.Language)]; !ok {
+		return judgeAPI.Program{}, INVALID_LANGUAGE_ERROR
+	}
+	if programData.SourceCode == "" {
+		return judgeAPI.Program{}, fmt.Errorf("%s source code was not provided", name)
+	}
+
+	return judgeAPI.Program{
+		Language: judgeAPI.JudgeLanguage(programData.Language),
+		Code:     programData.SourceCode,
+		Files:    files,
+	}, nil
+}
+
+func readUploadedFiles(headers []*multipart.FileHeader) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(headers))
+	for _, header := range headers {
+		f, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error opening file %s: %w", header.Filename, err)
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %s: %w", header.Filename, err)
+		}
+		files[header.Filename] = content
+	}
+	return files, nil
+}
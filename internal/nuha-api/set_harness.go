@@ -0,0 +1,97 @@
+package nuha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// setHarness sets the driver code of a function problem for one language,
+// the template has {{SOLUTION}} where the submitted function goes and the
+// starter code is what participants start from
+func setHarness(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	defer r.Body.Close()
+
+	harness := models.Harness{}
+	err = json.NewDecoder(r.Body).Decode(&harness)
+	if err != nil {
+		respondWithError(w, 400, INVALID_JSON_ERROR)
+		return err
+	}
+
+	if _, ok := judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(harness.Language)]; !ok {
+		respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+		return fmt.Errorf("harness language %d is not supported", harness.Language)
+	}
+	err = harness.Validate()
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+
+	_, err = pr.GetProblemInfo(id)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("Problem"))
+		return err
+	}
+
+	err = pr.SetHarness(id, &harness)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s harness for %s has been set", problemId, judgeAPI.JudgeLanguageDescription[judgeAPI.JudgeLanguage(harness.Language)]))
+	return nil
+}
+
+// deleteHarness stops a language from being used for a function problem
+func deleteHarness(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	language, err := strconv.Atoi(r.URL.Query().Get("language_id"))
+	if err != nil {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, language_id query was not provided: %w", err)
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+	err = pr.DeleteHarness(id, language)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s harness for language %d has been removed", problemId, language))
+	return nil
+}
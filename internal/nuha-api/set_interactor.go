@@ -0,0 +1,81 @@
+package nuha
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// setInteractor takes the testlib style interactor of an interactive problem,
+// it is run with the input and output file names and talks to the solution
+// over its stdin and stdout. it comes like a checker, as json or as an
+// interactor_file upload with language_id and interactor_files
+func setInteractor(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	defer r.Body.Close()
+
+	interactor, err := readJuryProgram(r, "interactor")
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+	if !judgeAPI.SupportsScripts(interactor.Language) {
+		respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+		return fmt.Errorf("interactor language %d can not run interactively", interactor.Language)
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+
+	_, err = pr.GetProblemInfo(id)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("Problem"))
+		return err
+	}
+
+	err = pr.SetInteractor(id, int(interactor.Language), interactor.Code, interactor.Files)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s interactor has been set", problemId))
+	return nil
+}
+
+func deleteInteractor(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+	err = pr.DeleteInteractor(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s interactor has been removed", problemId))
+	return nil
+}
@@ -0,0 +1,59 @@
+package nuha
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// setTestGroups replaces the test groups of a problem, test cases point at
+// them by number so they can be added before or after the groups
+func setTestGroups(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	defer r.Body.Close()
+
+	groups := []models.TestGroup{}
+	err = json.NewDecoder(r.Body).Decode(&groups)
+	if err != nil {
+		respondWithError(w, 400, INVALID_JSON_ERROR)
+		return err
+	}
+
+	err = models.ValidateTestGroups(groups)
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+
+	_, err = pr.GetProblemInfo(id)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("Problem"))
+		return err
+	}
+
+	err = pr.SetTestGroups(id, groups...)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	respondWithSuccess(w, 200, fmt.Sprintf("problem with id %s now has %d test groups", problemId, len(groups)))
+	return nil
+}
@@ -0,0 +1,85 @@
+package submissionsPL
+
+import (
+	"strings"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+const (
+	CHECKER_TIME_LIMIT   = 5      // seconds
+	CHECKER_MEMORY_LIMIT = 256000 // KB
+	CHECKER_INPUT_FILE   = "input.txt"
+	CHECKER_OUTPUT_FILE  = "output.txt"
+	CHECKER_ANSWER_FILE  = "answer.txt"
+
+	// testlib exit codes, anything else means the checker itself failed
+	CHECKER_OK                 = 0
+	CHECKER_WRONG_ANSWER       = 1
+	CHECKER_PRESENTATION_ERROR = 2
+	// quitf(_pc(n)) exits with 16+n, n is the percent of the test that passed
+	CHECKER_PARTIAL     = 16
+	CHECKER_MAX_PARTIAL = CHECKER_PARTIAL + 100
+)
+
+// Checker is a testlib style program that judges runs instead of comparing
+// outputs, it is run with the input, the participant output and the
+// jury answer as files and answers with its exit code
+type Checker struct {
+	Language judgeAPI.JudgeLanguage
+	Code     string
+	// put next to the checker source, like testlib.h
+	Files map[string][]byte
+}
+
+func (c *Checker) submission(tc models.Testcase, output string) (judgeAPI.Submission, error) {
+	files := make(map[string][]byte, len(c.Files)+3)
+	for name, content := range c.Files {
+		files[name] = content
+	}
+	files[CHECKER_INPUT_FILE] = []byte(tc.Stdin)
+	files[CHECKER_OUTPUT_FILE] = []byte(output)
+	files[CHECKER_ANSWER_FILE] = []byte(tc.ExpectedOutput)
+
+	additionalFiles, err := judgeAPI.EncodeAdditionalFiles(files)
+	if err != nil {
+		return judgeAPI.Submission{}, err
+	}
+
+	submission := judgeAPI.NewSubmission(c.Code, c.Language)
+	submission.SetAdditionalFiles(additionalFiles)
+	submission.SetCommandLineArguments(strings.Join([]string{CHECKER_INPUT_FILE, CHECKER_OUTPUT_FILE, CHECKER_ANSWER_FILE}, " "))
+	submission.SetCPUTimeLimit(CHECKER_TIME_LIMIT)
+	submission.SetMemoryLimit(CHECKER_MEMORY_LIMIT)
+	return *submission, nil
+}
+
+// applyChecker gives the run the verdict its check came back with, the
+// checker comment becomes the run message
+func applyChecker(run judgeAPI.Submission, check judgeAPI.Submission) judgeAPI.Submission {
+	statusID := judgeAPI.INTERNAL_ERROR_STATUS
+	message := strings.TrimSpace(check.Stderr)
+
+	switch {
+	case check.Status.ID == judgeAPI.ACCEPTED_STATUS:
+		statusID = judgeAPI.ACCEPTED_STATUS
+	case check.Status.ID == judgeAPI.RUNTIME_ERROR_NZEC_STATUS && (check.ExitCode == CHECKER_WRONG_ANSWER || check.ExitCode == CHECKER_PRESENTATION_ERROR):
+		statusID = judgeAPI.WRONG_ANSWER_STATUS
+	case check.Status.ID == judgeAPI.RUNTIME_ERROR_NZEC_STATUS && check.ExitCode >= CHECKER_PARTIAL && check.ExitCode <= CHECKER_MAX_PARTIAL:
+		statusID = judgeAPI.WRONG_ANSWER_STATUS
+		score := float64(check.ExitCode-CHECKER_PARTIAL) / 100
+		run.Score = &score
+	case check.Status.ID == judgeAPI.COMPILATION_ERROR_STATUS:
+		message = "checker did not compile: " + strings.TrimSpace(check.CompileOutput)
+	default:
+		message = "checker failed: " + strings.TrimSpace(check.Status.Description+" "+check.Message+" "+message)
+	}
+
+	run.Status = judgeAPI.SubmissionStatus{
+		ID:          statusID,
+		Description: judgeAPI.JudgeSubmissionStatusDescription[statusID],
+	}
+	run.Message = message
+	return run
+}
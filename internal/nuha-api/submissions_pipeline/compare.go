@@ -0,0 +1,94 @@
+package submissionsPL
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+// Comparison is how a run output is matched against the expected output,
+// judge zero only runs the code and nuha does the comparing
+type Comparison struct {
+	Mode    models.ComparisonMode
+	Epsilon float64
+}
+
+func (c Comparison) match(output string, expected string) bool {
+	switch c.Mode {
+	case models.EXACT_COMPARISON_MODE:
+		return output == expected
+	case models.TOKENS_COMPARISON_MODE:
+		return tokensMatch(output, expected, func(a, b string) bool { return a == b })
+	case models.CASE_INSENSITIVE_COMPARISON_MODE:
+		return strings.EqualFold(trimTrailingWhitespace(output), trimTrailingWhitespace(expected))
+	case models.FLOAT_COMPARISON_MODE:
+		return tokensMatch(output, expected, func(a, b string) bool { return floatsMatch(a, b, c.Epsilon) })
+	default:
+		return trimTrailingWhitespace(output) == trimTrailingWhitespace(expected)
+	}
+}
+
+// compare judges the accepted runs of update that were not compared or
+// checked yet, runs with a wrong output become wrong answers
+func (c Comparison) compare(update *DBUpdate) {
+	for i := update.Checked; i < len(update.Results) && i < len(update.Job.Testcases); i++ {
+		run := &update.Results[i]
+		if run.Status.ID != judgeAPI.ACCEPTED_STATUS || c.match(run.Stdout, update.Job.Testcases[i].ExpectedOutput) {
+			continue
+		}
+		run.Status = judgeAPI.SubmissionStatus{
+			ID:          judgeAPI.WRONG_ANSWER_STATUS,
+			Description: judgeAPI.JudgeSubmissionStatusDescription[judgeAPI.WRONG_ANSWER_STATUS],
+		}
+	}
+	update.Checked = len(update.Results)
+}
+
+func trimTrailingWhitespace(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func tokensMatch(output string, expected string, equal func(a, b string) bool) bool {
+	outputTokens := strings.Fields(output)
+	expectedTokens := strings.Fields(expected)
+	if len(outputTokens) != len(expectedTokens) {
+		return false
+	}
+
+	for i := range expectedTokens {
+		if !equal(outputTokens[i], expectedTokens[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// floatsMatch is testlib doubleCompare, tokens that are not numbers must
+// be the same
+func floatsMatch(a string, b string, epsilon float64) bool {
+	if a == b {
+		return true
+	}
+
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return math.IsNaN(x) && math.IsNaN(y)
+	}
+	if math.IsInf(y, 0) {
+		return x == y
+	}
+
+	diff := math.Abs(x - y)
+	return diff <= epsilon || diff <= epsilon*math.Abs(y)
+}
@@ -0,0 +1,39 @@
+package submissionsPL
+
+import (
+	"testing"
+
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+func TestComparisonMatch(t *testing.T) {
+	tests := []struct {
+		mode     models.ComparisonMode
+		output   string
+		expected string
+		want     bool
+	}{
+		{models.EXACT_COMPARISON_MODE, "1 2\n", "1 2\n", true},
+		{models.EXACT_COMPARISON_MODE, "1 2", "1 2\n", false},
+		{models.TRAILING_WHITESPACE_COMPARISON_MODE, "1 2  \r\n3\n\n", "1 2\n3", true},
+		{models.TRAILING_WHITESPACE_COMPARISON_MODE, "1  2\n3", "1 2\n3", false},
+		{models.TRAILING_WHITESPACE_COMPARISON_MODE, "1 2 3", "1 2\n3", false},
+		{"", "3 \n", "3", true},
+		{models.TOKENS_COMPARISON_MODE, "1  2\n3", "1 2 3\n", true},
+		{models.TOKENS_COMPARISON_MODE, "1 2", "1 2 3", false},
+		{models.CASE_INSENSITIVE_COMPARISON_MODE, "yes \n", "YES", true},
+		{models.CASE_INSENSITIVE_COMPARISON_MODE, "y e s", "YES", false},
+		{models.FLOAT_COMPARISON_MODE, "0.3333334 name", "0.333333 name", true},
+		{models.FLOAT_COMPARISON_MODE, "1000000.5", "1000000", true},
+		{models.FLOAT_COMPARISON_MODE, "0.334", "0.333333", false},
+		{models.FLOAT_COMPARISON_MODE, "1.0 other", "1.0 name", false},
+		{models.FLOAT_COMPARISON_MODE, "1.0", "1.0 2.0", false},
+	}
+
+	for _, tt := range tests {
+		c := Comparison{Mode: tt.mode, Epsilon: models.DEFAULT_COMPARISON_EPSILON}
+		if got := c.match(tt.output, tt.expected); got != tt.want {
+			t.Fatalf("%s comparison of %q with %q got %v, wanted %v", tt.mode, tt.output, tt.expected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package submissionsPL
+
+import (
+	"strings"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+)
+
+// applyInteractor turns the runs of an interactive job that were not judged
+// yet into verdicts, the run exit code is what the interactor decided. the
+// exchange moves to the query log and stdout keeps what the solution printed
+func applyInteractor(update *DBUpdate) {
+	for i := update.Checked; i < len(update.Results); i++ {
+		run := &update.Results[i]
+		run.QueryLog = run.Stdout
+		run.Stdout = judgeAPI.SolutionOutput(run.QueryLog)
+
+		if run.Status.ID != judgeAPI.RUNTIME_ERROR_NZEC_STATUS {
+			continue
+		}
+
+		statusID := run.Status.ID
+		switch run.ExitCode {
+		case judgeAPI.INTERACTOR_WRONG_ANSWER, judgeAPI.INTERACTOR_PRESENTATION_ERROR:
+			statusID = judgeAPI.WRONG_ANSWER_STATUS
+		case judgeAPI.INTERACTOR_FAILED:
+			statusID = judgeAPI.INTERNAL_ERROR_STATUS
+		}
+
+		run.Status = judgeAPI.SubmissionStatus{
+			ID:          statusID,
+			Description: judgeAPI.JudgeSubmissionStatusDescription[statusID],
+		}
+		run.Message = strings.TrimSpace(run.Stderr)
+	}
+	update.Checked = len(update.Results)
+}
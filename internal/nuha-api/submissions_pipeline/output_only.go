@@ -0,0 +1,36 @@
+package submissionsPL
+
+import (
+	"log"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+)
+
+// judgeOutputs judges the uploaded outputs of an output only job without
+// running anything, they go through the comparison or the checker like the
+// output of a run would
+func (sp *SubmissionsPipeline) judgeOutputs(job *SubmissionJob) {
+	results := make([]judgeAPI.Submission, len(job.Testcases))
+	for i, tc := range job.Testcases {
+		results[i] = judgeAPI.Submission{
+			Stdin:          tc.Stdin,
+			ExpectedOutput: tc.ExpectedOutput,
+			Status: judgeAPI.SubmissionStatus{
+				ID:          judgeAPI.ACCEPTED_STATUS,
+				Description: judgeAPI.JudgeSubmissionStatusDescription[judgeAPI.ACCEPTED_STATUS],
+			},
+		}
+		if i < len(job.Outputs) {
+			results[i].Stdout = job.Outputs[i]
+		}
+	}
+
+	err := sp.complete(&DBUpdate{
+		SubmissionID: job.SubmissionID,
+		Results:      results,
+		Job:          job,
+	})
+	if err != nil {
+		log.Printf("error judging submission %v outputs: %v", job.SubmissionID, err)
+	}
+}
@@ -0,0 +1,267 @@
+package submissionsPL
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// judge zero can call back before PostBatchSubmission returns the tokens,
+// results for tokens we do not know yet are kept around this long
+const UNMATCHED_RESULTS_TTL = time.Minute
+
+type pendingSubmission struct {
+	submissionID uuid.UUID
+	// the tokens we are waiting on, run tokens or checker tokens when checking
+	tokens    []string
+	testcases []models.Testcase
+	job       *SubmissionJob
+	finished  []judgeAPI.Submission
+	// runs being checked, with the token of the check of each run or
+	// nothing for runs that need no check
+	checking      []judgeAPI.Submission
+	checkerTokens []string
+	results       map[string]judgeAPI.Submission
+	checkCount    int
+	nextCheck     time.Time
+}
+
+type unmatchedResult struct {
+	result     judgeAPI.Submission
+	receivedAt time.Time
+}
+
+// pendingTracker is shared between the poller and the judge callbacks,
+// a submission leaves it once every one of its tokens has a final result
+type pendingTracker struct {
+	mu          sync.Mutex
+	submissions map[uuid.UUID]*pendingSubmission
+	owners      map[string]uuid.UUID
+	unmatched   map[string]unmatchedResult
+}
+
+func newPendingTracker() *pendingTracker {
+	return &pendingTracker{
+		submissions: make(map[uuid.UUID]*pendingSubmission),
+		owners:      make(map[string]uuid.UUID),
+		unmatched:   make(map[string]unmatchedResult),
+	}
+}
+
+// add starts tracking the tokens of a submission, if callbacks already
+// finished all of them the completed update is returned right away
+func (pt *pendingTracker) add(rt *ResultTokens, firstCheck time.Time) (*DBUpdate, bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pending := &pendingSubmission{
+		submissionID:  rt.SubmissionID,
+		tokens:        rt.Tokens[len(rt.Finished):],
+		testcases:     rt.Testcases,
+		job:           rt.Job,
+		finished:      rt.Finished,
+		checking:      rt.Checking,
+		checkerTokens: rt.CheckerTokens,
+		results:       make(map[string]judgeAPI.Submission),
+		nextCheck:     firstCheck,
+	}
+	if rt.Checking != nil {
+		pending.tokens = []string{}
+		for _, token := range rt.CheckerTokens {
+			if token != "" {
+				pending.tokens = append(pending.tokens, token)
+			}
+		}
+	}
+	pt.submissions[rt.SubmissionID] = pending
+
+	for _, token := range pending.tokens {
+		pt.owners[token] = rt.SubmissionID
+
+		if early, ok := pt.unmatched[token]; ok {
+			delete(pt.unmatched, token)
+			pt.recordLocked(pending, early.result)
+		}
+	}
+
+	return pt.completeLocked(pending)
+}
+
+// record stores a final result for a token, it returns the update for the
+// submission when this was the last result it was waiting for
+func (pt *pendingTracker) record(result judgeAPI.Submission) (*DBUpdate, bool) {
+	if !isSubmissionDone(result) {
+		return nil, false
+	}
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	submissionID, ok := pt.owners[result.Token]
+	if !ok {
+		pt.unmatched[result.Token] = unmatchedResult{result: result, receivedAt: time.Now()}
+		return nil, false
+	}
+
+	pending := pt.submissions[submissionID]
+	pt.recordLocked(pending, result)
+	return pt.completeLocked(pending)
+}
+
+// due returns the submissions that should be polled now, with only the
+// tokens that still have no final result
+func (pt *pendingTracker) due(now time.Time) map[uuid.UUID][]string {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	for token, early := range pt.unmatched {
+		if now.Sub(early.receivedAt) > UNMATCHED_RESULTS_TTL {
+			delete(pt.unmatched, token)
+		}
+	}
+
+	due := make(map[uuid.UUID][]string)
+	for id, pending := range pt.submissions {
+		if now.Before(pending.nextCheck) {
+			continue
+		}
+
+		unfinished := []string{}
+		for _, token := range pending.tokens {
+			if _, ok := pending.results[token]; !ok {
+				unfinished = append(unfinished, token)
+			}
+		}
+		due[id] = unfinished
+	}
+
+	return due
+}
+
+// checked counts a poll that did not finish the submission and schedules
+// the next one by policy, it returns how many checks were made so far
+func (pt *pendingTracker) checked(submissionID uuid.UUID, policy RetryPolicy) int {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pending, ok := pt.submissions[submissionID]
+	if !ok {
+		return 0
+	}
+
+	pending.checkCount += 1
+	pending.nextCheck = time.Now().Add(policy.Delay(pending.checkCount))
+	return pending.checkCount
+}
+
+// timeout stops tracking a submission the judge never finished, the update
+// carries whatever results did come in
+func (pt *pendingTracker) timeout(submissionID uuid.UUID) (*DBUpdate, bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pending, ok := pt.submissions[submissionID]
+	if !ok {
+		return nil, false
+	}
+
+	results := append([]judgeAPI.Submission{}, pending.finished...)
+	if pending.checking != nil {
+		// unchecked runs are not worth keeping as verdicts
+		results = append(results, pending.checking...)
+	} else {
+		for _, token := range pending.tokens {
+			if result, ok := pending.results[token]; ok {
+				results = append(results, result)
+			}
+		}
+	}
+
+	pt.removeLocked(pending)
+
+	return &DBUpdate{
+		SubmissionID: submissionID,
+		Results:      results,
+		Job:          pending.job,
+		Checked:      len(pending.finished),
+		TimedOut:     true,
+	}, true
+}
+
+// ids returns every submission still waiting for results
+func (pt *pendingTracker) ids() []uuid.UUID {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	ids := make([]uuid.UUID, 0, len(pt.submissions))
+	for id := range pt.submissions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (pt *pendingTracker) recordLocked(pending *pendingSubmission, result judgeAPI.Submission) {
+	if pending.checking != nil {
+		pending.results[result.Token] = result
+		return
+	}
+
+	// callbacks only carry the run output, the input side comes from the test case
+	for i, token := range pending.tokens {
+		i += len(pending.finished)
+		if token == result.Token && i < len(pending.testcases) {
+			if result.Stdin == "" {
+				result.Stdin = pending.testcases[i].Stdin
+			}
+			if result.ExpectedOutput == "" {
+				result.ExpectedOutput = pending.testcases[i].ExpectedOutput
+			}
+		}
+	}
+
+	pending.results[result.Token] = result
+}
+
+func (pt *pendingTracker) completeLocked(pending *pendingSubmission) (*DBUpdate, bool) {
+	if len(pending.results) < len(pending.tokens) {
+		return nil, false
+	}
+
+	results := append([]judgeAPI.Submission{}, pending.finished...)
+	if pending.checking == nil {
+		for _, token := range pending.tokens {
+			results = append(results, pending.results[token])
+		}
+	} else {
+		for i, run := range pending.checking {
+			if token := pending.checkerTokens[i]; token != "" {
+				run = applyChecker(run, pending.results[token])
+			}
+			results = append(results, run)
+		}
+	}
+
+	pt.removeLocked(pending)
+
+	checked := len(pending.finished)
+	if pending.checking != nil {
+		checked = len(results)
+	}
+
+	return &DBUpdate{
+		SubmissionID: pending.submissionID,
+		Results:      results,
+		Job:          pending.job,
+		Checked:      checked,
+	}, true
+}
+
+func (pt *pendingTracker) removeLocked(pending *pendingSubmission) {
+	for _, token := range pending.tokens {
+		delete(pt.owners, token)
+	}
+	delete(pt.submissions, pending.submissionID)
+}
@@ -0,0 +1,30 @@
+package submissionsPL
+
+import (
+	"math"
+	"time"
+)
+
+// RetryPolicy spaces out the checks of a submission the judge did not finish
+// yet, every wait is Multiplier times the one before it up to MaxDelay, and we
+// give up after MaxAttempts checks
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+}
+
+// Delay is how long to wait after attempt checks were already made
+func (rp RetryPolicy) Delay(attempt int) time.Duration {
+	multiplier := rp.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(rp.InitialDelay) * math.Pow(multiplier, float64(attempt))
+	if rp.MaxDelay > 0 && delay > float64(rp.MaxDelay) {
+		return rp.MaxDelay
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,22 @@
+package submissionsPL
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialDelay: time.Second, MaxDelay: 10 * time.Second, Multiplier: 2}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for attempt, delay := range want {
+		if got := policy.Delay(attempt); got != delay {
+			t.Fatalf("attempt %d got delay %v, wanted %v", attempt, got, delay)
+		}
+	}
+
+	fixed := RetryPolicy{InitialDelay: time.Second}
+	if got := fixed.Delay(5); got != time.Second {
+		t.Fatalf("policy without a multiplier got delay %v, wanted %v", got, time.Second)
+	}
+}
@@ -0,0 +1,66 @@
+package submissionsPL
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueuedJob is the oldest claimable job of one user
+type QueuedJob struct {
+	SubmissionID uuid.UUID
+	UserID       uuid.UUID
+	QueuedAt     time.Time
+}
+
+// scheduler decides which user gets the judge next, users take turns with
+// whoever waited longest since their last job going first, users at their
+// in flight cap wait for one to finish
+type scheduler struct {
+	mu          sync.Mutex
+	maxInFlight int
+	turn        uint64
+	lastServed  map[uuid.UUID]uint64
+}
+
+func newScheduler(maxInFlight int) *scheduler {
+	return &scheduler{
+		maxInFlight: maxInFlight,
+		lastServed:  make(map[uuid.UUID]uint64),
+	}
+}
+
+// order returns the candidates that may be claimed, best first
+func (s *scheduler) order(candidates []QueuedJob, inFlight map[uuid.UUID]int) []QueuedJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ordered := make([]QueuedJob, 0, len(candidates))
+	for _, c := range candidates {
+		if s.maxInFlight > 0 && inFlight[c.UserID] >= s.maxInFlight {
+			continue
+		}
+		ordered = append(ordered, c)
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if s.lastServed[a.UserID] != s.lastServed[b.UserID] {
+			return s.lastServed[a.UserID] < s.lastServed[b.UserID]
+		}
+		return a.QueuedAt.Before(b.QueuedAt)
+	})
+
+	return ordered
+}
+
+// served moves a user to the back of the line
+func (s *scheduler) served(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.turn += 1
+	s.lastServed[userID] = s.turn
+}
@@ -0,0 +1,49 @@
+package submissionsPL
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSchedulerTakesTurns(t *testing.T) {
+	s := newScheduler(2)
+	spammer, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	start := time.Now()
+
+	queued := map[uuid.UUID]time.Time{
+		spammer: start,
+		alice:   start.Add(time.Second),
+		bob:     start.Add(2 * time.Second),
+	}
+	candidates := func() []QueuedJob {
+		jobs := []QueuedJob{}
+		for user, at := range queued {
+			jobs = append(jobs, QueuedJob{SubmissionID: uuid.New(), UserID: user, QueuedAt: at})
+		}
+		return jobs
+	}
+
+	// the spammer got in first, after that everyone takes a turn
+	want := []uuid.UUID{spammer, alice, bob, spammer, alice, bob}
+	for i, user := range want {
+		next := s.order(candidates(), nil)[0]
+		if next.UserID != user {
+			t.Fatalf("pick %d went to the wrong user", i)
+		}
+		s.served(next.UserID)
+	}
+
+	// users at the cap are skipped
+	inFlight := map[uuid.UUID]int{spammer: 2}
+	ordered := s.order(candidates(), inFlight)
+	if len(ordered) != 2 {
+		t.Fatalf("got %d candidates, wanted the 2 users under the cap", len(ordered))
+	}
+	for _, job := range ordered {
+		if job.UserID == spammer {
+			t.Fatalf("user at the in flight cap was scheduled")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package submissionsPL
+
+import (
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+// calculateScore adds up the points of the test groups, groups come in number
+// order and results line up with testcases, tests without a result count as
+// failed. a group whose dependencies were not all fully passed scores nothing
+func calculateScore(testcases []models.Testcase, groups []models.TestGroup, mode models.ScoringMode, results []judgeAPI.Submission) float64 {
+	lowest := make(map[int]float64, len(groups))
+	for _, g := range groups {
+		lowest[g.Number] = 1
+	}
+
+	for i, tc := range testcases {
+		if _, ok := lowest[tc.Group]; !ok {
+			continue
+		}
+
+		score := 0.0
+		if i < len(results) {
+			score = testScore(results[i])
+		}
+		lowest[tc.Group] = min(lowest[tc.Group], score)
+	}
+
+	total := 0.0
+	passed := make(map[int]bool, len(groups))
+	for _, g := range groups {
+		dependenciesPassed := true
+		for _, d := range g.Dependencies {
+			dependenciesPassed = dependenciesPassed && passed[d]
+		}
+		if !dependenciesPassed {
+			continue
+		}
+
+		passed[g.Number] = lowest[g.Number] == 1
+
+		switch mode {
+		case models.MIN_SCORING_MODE:
+			total += g.Points * lowest[g.Number]
+		default:
+			if passed[g.Number] {
+				total += g.Points
+			}
+		}
+	}
+
+	return total
+}
+
+// testScore is 1 for an accepted test, the part a checker gave a partially
+// correct one and 0 for anything else
+func testScore(result judgeAPI.Submission) float64 {
+	if result.Status.ID == judgeAPI.ACCEPTED_STATUS {
+		return 1
+	}
+	if result.Score != nil {
+		return *result.Score
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package submissionsPL
+
+import (
+	"testing"
+
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+func TestCalculateScore(t *testing.T) {
+	// a sample outside any group, then groups 1, 2 and 3 where 3 needs 1 and 2
+	testcases := []models.Testcase{
+		{Stdin: "sample"},
+		{Stdin: "1a", Group: 1}, {Stdin: "1b", Group: 1},
+		{Stdin: "2a", Group: 2}, {Stdin: "2b", Group: 2},
+		{Stdin: "3a", Group: 3},
+	}
+	groups := []models.TestGroup{
+		{Number: 1, Points: 20},
+		{Number: 2, Points: 30},
+		{Number: 3, Points: 50, Dependencies: []int{1, 2}},
+	}
+
+	results := func(statuses ...judgeAPI.JudgeSubmissionStatusID) []judgeAPI.Submission {
+		submissions := make([]judgeAPI.Submission, len(statuses))
+		for i, status := range statuses {
+			submissions[i].Status.ID = status
+		}
+		return submissions
+	}
+
+	ac, wa := judgeAPI.ACCEPTED_STATUS, judgeAPI.WRONG_ANSWER_STATUS
+
+	tests := []struct {
+		name    string
+		results []judgeAPI.Submission
+		want    float64
+	}{
+		{"everything passed", results(ac, ac, ac, ac, ac, ac), 100},
+		{"sample does not count", results(wa, ac, ac, ac, ac, ac), 100},
+		{"one test fails its group", results(ac, ac, ac, ac, wa, ac), 20},
+		{"dependency failed", results(ac, wa, ac, ac, ac, ac), 30},
+		{"missing results fail", results(ac, ac, ac), 20},
+		{"nothing passed", results(wa, wa, wa, wa, wa, wa), 0},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []models.ScoringMode{models.SUM_SCORING_MODE, models.MIN_SCORING_MODE} {
+			if got := calculateScore(testcases, groups, mode, tt.results); got != tt.want {
+				t.Fatalf("%s with %s scoring got %v, wanted %v", tt.name, mode, got, tt.want)
+			}
+		}
+	}
+
+	// the checker gave half of test 2b, min scoring keeps half of group 2
+	// and sum scoring only counts fully passed groups
+	partial := results(ac, ac, ac, ac, ac, ac)
+	partial[4] = applyChecker(partial[4], judgeAPI.Submission{
+		Status:   judgeAPI.SubmissionStatus{ID: judgeAPI.RUNTIME_ERROR_NZEC_STATUS},
+		ExitCode: CHECKER_PARTIAL + 50,
+	})
+	if partial[4].Status.ID != wa {
+		t.Fatalf("partially correct run got status %v, wanted wrong answer", partial[4].Status)
+	}
+
+	if got := calculateScore(testcases, groups, models.MIN_SCORING_MODE, partial); got != 35 {
+		t.Fatalf("partial test with min scoring got %v, wanted 35", got)
+	}
+	if got := calculateScore(testcases, groups, models.SUM_SCORING_MODE, partial); got != 20 {
+		t.Fatalf("partial test with sum scoring got %v, wanted 20", got)
+	}
+}
@@ -0,0 +1,638 @@
+package submissionsPL
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	JOB_QUEUED    = "QUEUED"
+	JOB_SUBMITTED = "SUBMITTED"
+	JOB_DONE      = "DONE"
+	JOB_TIMED_OUT = "TIMED_OUT"
+	// the judge kept rejecting the job, it waits for an admin to retry it
+	JOB_DEAD = "DEAD"
+)
+
+// Store is where the pipeline keeps its jobs and judging results, jobs are
+// leased to whoever claims them so several instances can share one store
+type Store interface {
+	// EnqueueJob returns the job position in the queue, starting from 1
+	EnqueueJob(ctx context.Context, job *SubmissionJob) (int, error)
+	// CreateSubmissionJob creates the submission and enqueues its job together,
+	// it returns the new submission id and the job position in the queue. the
+	// job is loaded from the submission when it is claimed
+	CreateSubmissionJob(ctx context.Context, submission database.CreateSubmissionParams) (uuid.UUID, int, error)
+	// QueueDepth is how many jobs wait to be sent to the judge
+	QueueDepth(ctx context.Context) (int, error)
+	// QueuedJobs returns the oldest claimable job of up to limit users
+	QueuedJobs(ctx context.Context, limit int) ([]QueuedJob, error)
+	// InFlightJobs counts the jobs each user has claimed or at the judge
+	InFlightJobs(ctx context.Context) (map[uuid.UUID]int, error)
+	// ClaimJob returns nil when the job was claimed by someone else first, and
+	// a JobConfigError when it was claimed but can not be judged
+	ClaimJob(ctx context.Context, submissionID uuid.UUID, lease time.Duration) (*SubmissionJob, error)
+	// ClaimStalledJobs returns jobs sent to the judge whose lease ran out,
+	// with their judge tokens. jobs that fail to load are left out and their
+	// errors joined, the others are still returned
+	ClaimStalledJobs(ctx context.Context, lease time.Duration) ([]*SubmissionJob, error)
+	MarkJobSubmitted(ctx context.Context, submissionID uuid.UUID, tokens []string, lease time.Duration) error
+	RecordJobError(ctx context.Context, submissionID uuid.UUID, jobErr error, response string) error
+	DeadLetterJob(ctx context.Context, submissionID uuid.UUID, jobErr error, response string) error
+	// RequeueDeadJob returns sql.ErrNoRows when the job is not dead
+	RequeueDeadJob(ctx context.Context, submissionID uuid.UUID) error
+	RenewLeases(ctx context.Context, submissionIDs []uuid.UUID, lease time.Duration) error
+	// RecoverPendingSubmissions gives every submission still pending a job
+	// that will finish it, reusing judge tokens when there are any
+	RecoverPendingSubmissions(ctx context.Context) (RecoveryReport, error)
+	// SaveResults also finishes the job, as timed out for JUDGE_TIMEOUT.
+	// testcases are the tests the results ran on, in the same order
+	SaveResults(ctx context.Context, submissionID uuid.UUID, verdict Verdict, results []judgeAPI.Submission, testcases []models.Testcase) error
+	// LanguageSettings returns the default settings for languages without any
+	LanguageSettings(ctx context.Context, language judgeAPI.JudgeLanguage) (*models.LanguageSettings, error)
+}
+
+// JobConfigError is a job that can not be judged the way its problem is set
+// up, like an interactive problem without an interactor or a function problem
+// whose harness was deleted. claiming it again does not help until an admin
+// fixes the problem
+type JobConfigError struct {
+	SubmissionID uuid.UUID
+	Err          error
+}
+
+func (e *JobConfigError) Error() string {
+	return fmt.Sprintf("submission %v can not be judged: %v", e.SubmissionID, e.Err)
+}
+
+func (e *JobConfigError) Unwrap() error {
+	return e.Err
+}
+
+// withSubmission fills in the submission of a JobConfigError that came from
+// loading its problem
+func withSubmission(err error, submissionID uuid.UUID) error {
+	configErr := &JobConfigError{}
+	if errors.As(err, &configErr) {
+		configErr.SubmissionID = submissionID
+	}
+	return err
+}
+
+// RecoveryReport counts the pending submissions found on startup, recovered
+// ones keep polling the judge with their tokens, rejudged ones are sent again
+type RecoveryReport struct {
+	Recovered   int       `json:"recovered"`
+	Rejudged    int       `json:"rejudged"`
+	DeadLetters int       `json:"dead_letters"`
+	RecoveredAt time.Time `json:"recovered_at"`
+}
+
+type DBStore struct {
+	db        *sql.DB
+	dbQueries *database.Queries
+}
+
+func NewDBStore(db *sql.DB, dbQueries *database.Queries) *DBStore {
+	return &DBStore{
+		db:        db,
+		dbQueries: dbQueries,
+	}
+}
+
+func (s *DBStore) EnqueueJob(ctx context.Context, job *SubmissionJob) (int, error) {
+	_, err := s.dbQueries.CreateJudgeJob(ctx, job.SubmissionID)
+	if err != nil {
+		return 0, err
+	}
+
+	position, err := s.dbQueries.GetJudgeJobQueuePosition(ctx, job.SubmissionID)
+	return int(position), err
+}
+
+func (s *DBStore) CreateSubmissionJob(ctx context.Context, submission database.CreateSubmissionParams) (uuid.UUID, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	txq := s.dbQueries.WithTx(tx)
+
+	submissionDB, err := txq.CreateSubmission(ctx, submission)
+	if err != nil {
+		return uuid.Nil, 0, fmt.Errorf("error creating submission in database: %w", err)
+	}
+
+	_, err = txq.CreateJudgeJob(ctx, submissionDB.ID)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+
+	position, err := txq.GetJudgeJobQueuePosition(ctx, submissionDB.ID)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+
+	return submissionDB.ID, int(position), tx.Commit()
+}
+
+func (s *DBStore) QueueDepth(ctx context.Context) (int, error) {
+	depth, err := s.dbQueries.CountQueuedJudgeJobs(ctx)
+	return int(depth), err
+}
+
+func (s *DBStore) QueuedJobs(ctx context.Context, limit int) ([]QueuedJob, error) {
+	heads, err := s.dbQueries.GetQueuedJudgeJobHeads(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]QueuedJob, len(heads))
+	for i, head := range heads {
+		jobs[i] = QueuedJob{
+			SubmissionID: head.SubmissionID,
+			UserID:       head.UserID,
+			QueuedAt:     head.CreatedAt,
+		}
+	}
+
+	return jobs, nil
+}
+
+func (s *DBStore) InFlightJobs(ctx context.Context) (map[uuid.UUID]int, error) {
+	counts, err := s.dbQueries.CountInFlightJudgeJobsByUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inFlight := make(map[uuid.UUID]int, len(counts))
+	for _, c := range counts {
+		inFlight[c.UserID] = int(c.InFlight)
+	}
+
+	return inFlight, nil
+}
+
+func (s *DBStore) ClaimJob(ctx context.Context, submissionID uuid.UUID, lease time.Duration) (*SubmissionJob, error) {
+	params := database.ClaimQueuedJudgeJobParams{
+		LeaseSeconds: lease.Seconds(),
+		SubmissionID: submissionID,
+	}
+	judgeJob, err := s.dbQueries.ClaimQueuedJudgeJob(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadJob(ctx, judgeJob)
+}
+
+func (s *DBStore) ClaimStalledJobs(ctx context.Context, lease time.Duration) ([]*SubmissionJob, error) {
+	judgeJobs, err := s.dbQueries.ClaimStalledJudgeJobs(ctx, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*SubmissionJob, 0, len(judgeJobs))
+	loadErrs := []error{}
+	for _, judgeJob := range judgeJobs {
+		job, err := s.loadJob(ctx, judgeJob)
+		if err != nil {
+			loadErrs = append(loadErrs, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, errors.Join(loadErrs...)
+}
+
+// loadJob fills a job row with what the judge needs from its submission and problem
+func (s *DBStore) loadJob(ctx context.Context, judgeJob database.JudgeJob) (*SubmissionJob, error) {
+	submission, err := s.dbQueries.GetSubmissionByID(ctx, judgeJob.SubmissionID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting submission %v: %w", judgeJob.SubmissionID, err)
+	}
+
+	problem, err := s.dbQueries.GetProblemByID(ctx, submission.ProblemID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting problem %v: %w", submission.ProblemID, err)
+	}
+
+	testcases, err := s.dbQueries.GetTestCases(ctx, problem.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting problem %v test cases: %w", problem.ID, err)
+	}
+
+	testGroups, err := s.dbQueries.GetTestGroups(ctx, problem.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting problem %v test groups: %w", problem.ID, err)
+	}
+
+	var checker *Checker
+	var interactor *judgeAPI.Program
+	if models.ProblemType(problem.ProblemType) == models.INTERACTIVE_PROBLEM_TYPE {
+		interactor, err = s.loadInteractor(ctx, problem.ID)
+	} else {
+		checker, err = s.loadChecker(ctx, problem.ID)
+	}
+	if err != nil {
+		return nil, withSubmission(err, submission.ID)
+	}
+
+	var outputs []string
+	var files map[string][]byte
+	if models.ProblemType(problem.ProblemType) == models.OUTPUT_ONLY_PROBLEM_TYPE {
+		outputs, err = loadOutputs(submission, len(testcases))
+	} else if len(submission.Files) > 0 {
+		files, err = judgeAPI.UnzipFiles(submission.Files)
+	}
+	if err != nil {
+		return nil, &JobConfigError{SubmissionID: submission.ID, Err: fmt.Errorf("error reading submission files: %w", err)}
+	}
+
+	var harness *models.Harness
+	if models.ProblemType(problem.ProblemType) == models.FUNCTION_PROBLEM_TYPE {
+		harness, err = s.loadHarness(ctx, problem.ID, submission.Language)
+		if err != nil {
+			return nil, withSubmission(err, submission.ID)
+		}
+	}
+
+	return &SubmissionJob{
+		SubmissionID: submission.ID,
+		UserID:       submission.UserID,
+		Language:     judgeAPI.JudgeLanguage(submission.Language),
+		Code:         submission.SourceCode,
+		Files:        files,
+		EntryPoint:   submission.EntryPoint,
+		Timelimit:    problem.TimeLimit,
+		MemoryLimit:  problem.MemoryLimit,
+		ProblemID:    problem.ID,
+		Testcases:    models.TestCasesFromDBObjects(testcases),
+		JudgingMode:  models.JudgingMode(problem.JudgingMode),
+		TestGroups:   models.TestGroupsFromDBObjects(testGroups),
+		ScoringMode:  models.ScoringMode(problem.ScoringMode),
+		Comparison: Comparison{
+			Mode:    models.ComparisonMode(problem.ComparisonMode),
+			Epsilon: problem.ComparisonEpsilon,
+		},
+		Checker:    checker,
+		Interactor: interactor,
+		Harness:    harness,
+		Outputs:    outputs,
+		Tokens:     judgeJob.Tokens,
+		Attempts:   int(judgeJob.Attempts),
+	}, nil
+}
+
+func (s *DBStore) loadChecker(ctx context.Context, problemID uuid.UUID) (*Checker, error) {
+	problemChecker, err := s.dbQueries.GetProblemChecker(ctx, problemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting problem %v checker: %w", problemID, err)
+	}
+
+	files, err := judgeAPI.UnzipFiles(problemChecker.Files)
+	if err != nil {
+		return nil, &JobConfigError{Err: fmt.Errorf("error reading problem %v checker files: %w", problemID, err)}
+	}
+
+	return &Checker{
+		Language: judgeAPI.JudgeLanguage(problemChecker.Language),
+		Code:     problemChecker.SourceCode,
+		Files:    files,
+	}, nil
+}
+
+func (s *DBStore) loadInteractor(ctx context.Context, problemID uuid.UUID) (*judgeAPI.Program, error) {
+	problemInteractor, err := s.dbQueries.GetProblemInteractor(ctx, problemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &JobConfigError{Err: fmt.Errorf("interactive problem %v has no interactor", problemID)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting problem %v interactor: %w", problemID, err)
+	}
+
+	files, err := judgeAPI.UnzipFiles(problemInteractor.Files)
+	if err != nil {
+		return nil, &JobConfigError{Err: fmt.Errorf("error reading problem %v interactor files: %w", problemID, err)}
+	}
+
+	return &judgeAPI.Program{
+		Language: judgeAPI.JudgeLanguage(problemInteractor.Language),
+		Code:     problemInteractor.SourceCode,
+		Files:    files,
+	}, nil
+}
+
+func (s *DBStore) loadHarness(ctx context.Context, problemID uuid.UUID, language int32) (*models.Harness, error) {
+	params := database.GetProblemHarnessParams{
+		ProblemID: problemID,
+		Language:  language,
+	}
+	problemHarness, err := s.dbQueries.GetProblemHarness(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &JobConfigError{Err: fmt.Errorf("function problem %v has no harness for language %d", problemID, language)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting problem %v harness: %w", problemID, err)
+	}
+
+	return models.HarnessFromDBObject(&problemHarness), nil
+}
+
+func (s *DBStore) LanguageSettings(ctx context.Context, language judgeAPI.JudgeLanguage) (*models.LanguageSettings, error) {
+	lr := repositories.NewLanguageRepository(s.db, s.dbQueries, ctx)
+	return lr.GetSettings(int(language))
+}
+
+// loadOutputs reads the uploaded outputs of an output only submission, tests
+// without an output file get an empty one
+func loadOutputs(submission database.Submission, tests int) ([]string, error) {
+	files, err := judgeAPI.UnzipFiles(submission.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]string, tests)
+	for i := range outputs {
+		outputs[i] = string(files[models.OutputFileName(i+1)])
+	}
+	return outputs, nil
+}
+
+func (s *DBStore) MarkJobSubmitted(ctx context.Context, submissionID uuid.UUID, tokens []string, lease time.Duration) error {
+	params := database.SetJudgeJobSubmittedParams{
+		Tokens:       tokens,
+		LeaseSeconds: lease.Seconds(),
+		SubmissionID: submissionID,
+	}
+	_, err := s.dbQueries.SetJudgeJobSubmitted(ctx, params)
+	return err
+}
+
+func (s *DBStore) RecordJobError(ctx context.Context, submissionID uuid.UUID, jobErr error, response string) error {
+	params := database.SetJudgeJobErrorParams{
+		SubmissionID: submissionID,
+		LastError:    jobErr.Error(),
+		LastResponse: response,
+	}
+	_, err := s.dbQueries.SetJudgeJobError(ctx, params)
+	return err
+}
+
+func (s *DBStore) DeadLetterJob(ctx context.Context, submissionID uuid.UUID, jobErr error, response string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	txq := s.dbQueries.WithTx(tx)
+
+	params := database.SetJudgeJobErrorParams{
+		SubmissionID: submissionID,
+		LastError:    jobErr.Error(),
+		LastResponse: response,
+	}
+	_, err = txq.SetJudgeJobError(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	_, err = txq.SetJudgeJobState(ctx, database.SetJudgeJobStateParams{SubmissionID: submissionID, State: JOB_DEAD})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *DBStore) RequeueDeadJob(ctx context.Context, submissionID uuid.UUID) error {
+	_, err := s.dbQueries.RequeueDeadJudgeJob(ctx, submissionID)
+	return err
+}
+
+func (s *DBStore) RenewLeases(ctx context.Context, submissionIDs []uuid.UUID, lease time.Duration) error {
+	params := database.RenewJudgeJobsLeaseParams{
+		LeaseSeconds:  lease.Seconds(),
+		SubmissionIds: submissionIDs,
+	}
+	return s.dbQueries.RenewJudgeJobsLease(ctx, params)
+}
+
+func (s *DBStore) RecoverPendingSubmissions(ctx context.Context) (RecoveryReport, error) {
+	report := RecoveryReport{RecoveredAt: time.Now()}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return report, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	txq := s.dbQueries.WithTx(tx)
+
+	pending, err := txq.GetPendingSubmissionsForRecovery(ctx)
+	if err != nil {
+		return report, fmt.Errorf("error getting pending submissions: %w", err)
+	}
+
+	for _, p := range pending {
+		switch {
+		case p.JobState.Valid && p.JobState.String == JOB_SUBMITTED:
+			// claimed as stalled once the lease of whoever sent it runs out
+			report.Recovered += 1
+
+		case p.JobState.Valid && p.JobState.String == JOB_QUEUED:
+			report.Rejudged += 1
+
+		case p.JobState.Valid && p.JobState.String == JOB_DEAD:
+			// left for an admin to retry or fail
+			report.DeadLetters += 1
+
+		case p.JobState.Valid:
+			// the job finished without the submission getting a verdict
+			_, err = txq.SetJudgeJobState(ctx, database.SetJudgeJobStateParams{SubmissionID: p.ID, State: JOB_QUEUED})
+			if err != nil {
+				return report, fmt.Errorf("error requeuing submission %v job: %w", p.ID, err)
+			}
+			report.Rejudged += 1
+
+		case len(p.ResultTokens) > 0:
+			_, err = txq.CreateJudgeJob(ctx, p.ID)
+			if err != nil {
+				return report, fmt.Errorf("error creating submission %v job: %w", p.ID, err)
+			}
+
+			// an expired lease so the job is claimed as stalled right away
+			params := database.SetJudgeJobSubmittedParams{
+				Tokens:       p.ResultTokens,
+				LeaseSeconds: 0,
+				SubmissionID: p.ID,
+			}
+			_, err = txq.SetJudgeJobSubmitted(ctx, params)
+			if err != nil {
+				return report, fmt.Errorf("error saving submission %v judge tokens: %w", p.ID, err)
+			}
+			report.Recovered += 1
+
+		default:
+			_, err = txq.CreateJudgeJob(ctx, p.ID)
+			if err != nil {
+				return report, fmt.Errorf("error creating submission %v job: %w", p.ID, err)
+			}
+			report.Rejudged += 1
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return report, fmt.Errorf("error commiting recovered submission jobs: %w", err)
+	}
+
+	return report, nil
+}
+
+func (s *DBStore) SaveResults(ctx context.Context, submissionID uuid.UUID, verdict Verdict, results []judgeAPI.Submission, testcases []models.Testcase) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	txq := s.dbQueries.WithTx(tx)
+
+	createResultsParams := getResultColumns(submissionID, results, testcases)
+	_, err = txq.CreateSubmissionResults(ctx, createResultsParams)
+	if err != nil {
+		return fmt.Errorf("error creating submission results: %w", err)
+	}
+
+	updateSubmissionVerdict := database.UpdateSubmissionVerdictParams{
+		ID:     submissionID,
+		Status: string(verdict.Status),
+		FailedTest: sql.NullInt32{
+			Int32: int32(verdict.FailedTest),
+			Valid: verdict.FailedTest > 0,
+		},
+	}
+	if verdict.Score != nil {
+		updateSubmissionVerdict.Score = sql.NullFloat64{Float64: *verdict.Score, Valid: true}
+	}
+	_, err = txq.UpdateSubmissionVerdict(ctx, updateSubmissionVerdict)
+	if err != nil {
+		return fmt.Errorf("error updating submission status: %w", err)
+	}
+
+	setJobState := database.SetJudgeJobStateParams{
+		SubmissionID: submissionID,
+		State:        JOB_DONE,
+	}
+	if verdict.Status == models.JUDGE_TIMEOUT_SUBMISSION_STATUS {
+		setJobState.State = JOB_TIMED_OUT
+	}
+	_, err = txq.SetJudgeJobState(ctx, setJobState)
+	if err != nil {
+		return fmt.Errorf("error marking submission job as done: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("errror commitng transaction to add submissions resluts and update status: %w", err)
+	}
+
+	return nil
+}
+
+// results are capped before saving, a run that prints megabytes to stderr
+// or a compiler that spews errors would bloat every results query
+const MAX_RESULT_TEXT_SIZE = 64 << 10
+
+// getResultColumns keeps the visibility each test had, the tests of the
+// problem can be replaced after and results are shown by it
+func getResultColumns(submissionID uuid.UUID, results []judgeAPI.Submission, testcases []models.Testcase) database.CreateSubmissionResultsParams {
+	n := len(results)
+	params := database.CreateSubmissionResultsParams{
+		SubmissionID:    submissionID,
+		Tokens:          make([]string, n),
+		Stdins:          make([]string, n),
+		Stdouts:         make([]string, n),
+		Expectedoutputs: make([]string, n),
+		Statuses:        make([]int32, n),
+		Times:           make([]string, n),
+		Memories:        make([]float64, n),
+		Responses:       make([][]byte, n),
+		QueryLogs:       make([]string, n),
+		TestNumbers:     make([]int32, n),
+		CompileOutputs:  make([]string, n),
+		Stderrs:         make([]string, n),
+		Messages:        make([]string, n),
+		ExitCodes:       make([]int32, n),
+		ExitSignals:     make([]int32, n),
+		Visibilities:    make([]string, n),
+	}
+
+	for i, result := range results {
+		params.Tokens[i] = result.Token
+		params.Stdins[i] = result.Stdin
+		params.Stdouts[i] = result.Stdout
+		params.Expectedoutputs[i] = result.ExpectedOutput
+		params.Statuses[i] = int32(result.Status.ID)
+		params.Times[i] = result.Time
+		params.Memories[i] = result.Memory
+		params.Responses[i] = judgeResponse(result)
+		params.QueryLogs[i] = capText(result.QueryLog)
+		// results are always in test order, judging only ever stops early
+		params.TestNumbers[i] = int32(i + 1)
+		params.CompileOutputs[i] = capText(result.CompileOutput)
+		params.Stderrs[i] = capText(result.Stderr)
+		params.Messages[i] = capText(result.Message)
+		params.ExitCodes[i] = int32(result.ExitCode)
+		params.ExitSignals[i] = int32(result.ExitSignal)
+		params.Visibilities[i] = string(models.HIDDEN_TEST_VISIBILITY)
+		if i < len(testcases) {
+			params.Visibilities[i] = string(testcases[i].GetVisibility())
+		}
+	}
+
+	return params
+}
+
+// judgeResponse is the judge's response without the texts that have their
+// own columns, or the source and files the submission already has, so it
+// stays small however much the run printed
+func judgeResponse(result judgeAPI.Submission) []byte {
+	result.SourceCode = ""
+	result.AdditionalFiles = ""
+	result.Stdin = ""
+	result.ExpectedOutput = ""
+	result.Stdout = ""
+	result.Stderr = ""
+	result.CompileOutput = ""
+	result.Message = ""
+	result.QueryLog = ""
+	return result.JSON()
+}
+
+func capText(s string) string {
+	if len(s) <= MAX_RESULT_TEXT_SIZE {
+		return s
+	}
+	return strings.ToValidUTF8(s[:MAX_RESULT_TEXT_SIZE], "")
+}
@@ -0,0 +1,348 @@
+package submissionsPL
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type savedResults struct {
+	submissionID uuid.UUID
+	status       models.SubmissionStatus
+	failedTest   int
+	score        *float64
+	results      []judgeAPI.Submission
+}
+
+type memJob struct {
+	job          *SubmissionJob
+	state        string
+	queuedAt     time.Time
+	leaseExpires time.Time
+	lastError    string
+	lastResponse string
+	// set to make claiming the job fail like a problem missing its interactor
+	loadErr error
+}
+
+// memStore is a Store kept in memory with the same lease rules as DBStore
+type memStore struct {
+	mu        sync.Mutex
+	jobs      []*memJob
+	saved     chan savedResults
+	languages map[judgeAPI.JudgeLanguage]*models.LanguageSettings
+	// the tests of problems, jobs of new submissions are made with them
+	problems map[uuid.UUID][]models.Testcase
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		saved:     make(chan savedResults, 100),
+		languages: make(map[judgeAPI.JudgeLanguage]*models.LanguageSettings),
+		problems:  make(map[uuid.UUID][]models.Testcase),
+	}
+}
+
+func (s *memStore) EnqueueJob(ctx context.Context, job *SubmissionJob) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &memJob{job: job, state: JOB_QUEUED, queuedAt: time.Now()})
+	return s.queueDepthLocked(), nil
+}
+
+func (s *memStore) CreateSubmissionJob(ctx context.Context, submission database.CreateSubmissionParams) (uuid.UUID, int, error) {
+	s.mu.Lock()
+	testcases := s.problems[submission.ProblemID]
+	s.mu.Unlock()
+
+	job := &SubmissionJob{
+		SubmissionID: uuid.New(),
+		UserID:       submission.UserID,
+		Language:     judgeAPI.JudgeLanguage(submission.Language),
+		Code:         submission.SourceCode,
+		EntryPoint:   submission.EntryPoint,
+		Timelimit:    1,
+		MemoryLimit:  128000,
+		ProblemID:    submission.ProblemID,
+		Testcases:    testcases,
+	}
+	position, err := s.EnqueueJob(ctx, job)
+	return job.SubmissionID, position, err
+}
+
+func (s *memStore) setProblemTests(problemID uuid.UUID, testcases ...models.Testcase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.problems[problemID] = testcases
+}
+
+func (s *memStore) QueueDepth(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queueDepthLocked(), nil
+}
+
+func (s *memStore) queueDepthLocked() int {
+	depth := 0
+	for _, mj := range s.jobs {
+		if mj.state == JOB_QUEUED {
+			depth += 1
+		}
+	}
+	return depth
+}
+
+func (s *memStore) QueuedJobs(ctx context.Context, limit int) ([]QueuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	jobs := []QueuedJob{}
+	seen := make(map[uuid.UUID]bool)
+	for _, mj := range s.jobs {
+		if mj.state != JOB_QUEUED || !now.After(mj.leaseExpires) || seen[mj.job.UserID] {
+			continue
+		}
+		seen[mj.job.UserID] = true
+		jobs = append(jobs, QueuedJob{
+			SubmissionID: mj.job.SubmissionID,
+			UserID:       mj.job.UserID,
+			QueuedAt:     mj.queuedAt,
+		})
+		if len(jobs) == limit {
+			break
+		}
+	}
+
+	return jobs, nil
+}
+
+func (s *memStore) InFlightJobs(ctx context.Context) (map[uuid.UUID]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	inFlight := make(map[uuid.UUID]int)
+	for _, mj := range s.jobs {
+		if mj.state == JOB_SUBMITTED || (mj.state == JOB_QUEUED && now.Before(mj.leaseExpires)) {
+			inFlight[mj.job.UserID] += 1
+		}
+	}
+
+	return inFlight, nil
+}
+
+func (s *memStore) ClaimJob(ctx context.Context, submissionID uuid.UUID, lease time.Duration) (*SubmissionJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, mj := range s.jobs {
+		if mj.job.SubmissionID == submissionID && mj.state == JOB_QUEUED && now.After(mj.leaseExpires) {
+			mj.leaseExpires = now.Add(lease)
+			mj.job.Attempts += 1
+			if mj.loadErr != nil {
+				return nil, &JobConfigError{SubmissionID: submissionID, Err: mj.loadErr}
+			}
+			job := *mj.job
+			return &job, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *memStore) ClaimStalledJobs(ctx context.Context, lease time.Duration) ([]*SubmissionJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	jobs := []*SubmissionJob{}
+	loadErrs := []error{}
+	for _, mj := range s.jobs {
+		if mj.state == JOB_SUBMITTED && now.After(mj.leaseExpires) {
+			mj.leaseExpires = now.Add(lease)
+			if mj.loadErr != nil {
+				loadErrs = append(loadErrs, &JobConfigError{SubmissionID: mj.job.SubmissionID, Err: mj.loadErr})
+				continue
+			}
+			job := *mj.job
+			jobs = append(jobs, &job)
+		}
+	}
+
+	return jobs, errors.Join(loadErrs...)
+}
+
+func (s *memStore) MarkJobSubmitted(ctx context.Context, submissionID uuid.UUID, tokens []string, lease time.Duration) error {
+	return s.update(submissionID, func(mj *memJob) {
+		mj.state = JOB_SUBMITTED
+		mj.job.Tokens = tokens
+		mj.leaseExpires = time.Now().Add(lease)
+		mj.lastError = ""
+	})
+}
+
+func (s *memStore) RecordJobError(ctx context.Context, submissionID uuid.UUID, jobErr error, response string) error {
+	return s.update(submissionID, func(mj *memJob) {
+		mj.lastError = jobErr.Error()
+		mj.lastResponse = response
+	})
+}
+
+func (s *memStore) DeadLetterJob(ctx context.Context, submissionID uuid.UUID, jobErr error, response string) error {
+	return s.update(submissionID, func(mj *memJob) {
+		mj.state = JOB_DEAD
+		mj.lastError = jobErr.Error()
+		mj.lastResponse = response
+	})
+}
+
+func (s *memStore) RequeueDeadJob(ctx context.Context, submissionID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, mj := range s.jobs {
+		if mj.job.SubmissionID == submissionID && mj.state == JOB_DEAD {
+			mj.state = JOB_QUEUED
+			mj.job.Attempts = 0
+			mj.leaseExpires = time.Time{}
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (s *memStore) RenewLeases(ctx context.Context, submissionIDs []uuid.UUID, lease time.Duration) error {
+	for _, id := range submissionIDs {
+		s.update(id, func(mj *memJob) {
+			if mj.state == JOB_SUBMITTED {
+				mj.leaseExpires = time.Now().Add(lease)
+			}
+		})
+	}
+	return nil
+}
+
+func (s *memStore) RecoverPendingSubmissions(ctx context.Context) (RecoveryReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := RecoveryReport{RecoveredAt: time.Now()}
+	for _, mj := range s.jobs {
+		switch mj.state {
+		case JOB_SUBMITTED:
+			report.Recovered += 1
+		case JOB_QUEUED:
+			report.Rejudged += 1
+		case JOB_DEAD:
+			report.DeadLetters += 1
+		}
+	}
+	return report, nil
+}
+
+func (s *memStore) SaveResults(ctx context.Context, submissionID uuid.UUID, verdict Verdict, results []judgeAPI.Submission, testcases []models.Testcase) error {
+	err := s.update(submissionID, func(mj *memJob) {
+		mj.state = JOB_DONE
+		if verdict.Status == models.JUDGE_TIMEOUT_SUBMISSION_STATUS {
+			mj.state = JOB_TIMED_OUT
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	s.saved <- savedResults{submissionID: submissionID, status: verdict.Status, failedTest: verdict.FailedTest, score: verdict.Score, results: results}
+	return nil
+}
+
+func (s *memStore) LanguageSettings(ctx context.Context, language judgeAPI.JudgeLanguage) (*models.LanguageSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if settings, ok := s.languages[language]; ok {
+		return settings, nil
+	}
+	return models.DefaultLanguageSettings(int(language)), nil
+}
+
+func (s *memStore) setLanguageSettings(settings *models.LanguageSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.languages[judgeAPI.JudgeLanguage(settings.Language)] = settings
+}
+
+func (s *memStore) job(submissionID uuid.UUID) memJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, mj := range s.jobs {
+		if mj.job.SubmissionID == submissionID {
+			return *mj
+		}
+	}
+	return memJob{}
+}
+
+func (s *memStore) update(submissionID uuid.UUID, change func(mj *memJob)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, mj := range s.jobs {
+		if mj.job.SubmissionID == submissionID {
+			change(mj)
+			return nil
+		}
+	}
+	return fmt.Errorf("no job for submission %v", submissionID)
+}
+
+func TestGetResultColumns(t *testing.T) {
+	// é is two bytes, the cap falls in the middle of the last one
+	long := strings.Repeat("a", MAX_RESULT_TEXT_SIZE-1) + "é"
+	result := judgeAPI.Submission{
+		Token:         "token",
+		Stdout:        long,
+		Stderr:        long,
+		CompileOutput: long,
+		QueryLog:      long,
+		Message:       "short",
+		Status:        judgeAPI.SubmissionStatus{ID: judgeAPI.RUNTIME_ERROR_NZEC_STATUS},
+	}
+	sample := *models.NewTestCase("1", "1")
+	sample.Visibility = models.SAMPLE_TEST_VISIBILITY
+	params := getResultColumns(uuid.New(), []judgeAPI.Submission{result, result}, []models.Testcase{sample})
+
+	want := strings.Repeat("a", MAX_RESULT_TEXT_SIZE-1)
+	if params.Stderrs[0] != want || params.CompileOutputs[0] != want || params.QueryLogs[0] != want {
+		t.Fatalf("got stderr of %d bytes, compile output of %d and query log of %d, wanted all cut to %d", len(params.Stderrs[0]), len(params.CompileOutputs[0]), len(params.QueryLogs[0]), len(want))
+	}
+	if !utf8.ValidString(params.Stderrs[0]) || params.Messages[0] != "short" {
+		t.Fatalf("got invalid or changed texts")
+	}
+
+	if params.Visibilities[0] != string(models.SAMPLE_TEST_VISIBILITY) || params.Visibilities[1] != string(models.HIDDEN_TEST_VISIBILITY) {
+		t.Fatalf("got visibilities %v, wanted the sample kept and the unknown test hidden", params.Visibilities)
+	}
+	if len(params.Responses[0]) > 1024 {
+		t.Fatalf("got a judge response of %d bytes, wanted it without the outputs", len(params.Responses[0]))
+	}
+	response := judgeAPI.Submission{}
+	if err := json.Unmarshal(params.Responses[0], &response); err != nil || response.Token != "token" || response.Status.ID != result.Status.ID {
+		t.Fatalf("got response %+v and error %v", response, err)
+	}
+}
@@ -2,7 +2,7 @@ package submissionsPL
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -15,33 +15,106 @@ import (
 )
 
 const (
-	WAIT_TILL_CHANNEL_BUFFER_OPENS      = 5 // seconds
 	SUBMISSIONS_PROCESSORS_COUNT        = 5
-	RESULTS_PROCESSORS_COUNT            = 5
 	DB_WRITER_COUNT                     = 1
 	CHECK_WITH_JUDGEAPI_COUNT           = 5
 	CHANNELS_BUFFER                     = 100
-	PERIOD_BETWEEN_EACH_JUDGE_API_CHECK = 3 // seconds
+	PERIOD_BETWEEN_EACH_JUDGE_API_CHECK = 3  // seconds
+	MAX_PERIOD_BETWEEN_JUDGE_API_CHECKS = 30 // seconds
+	JUDGE_API_CHECKS_BACKOFF            = 2
+	MAX_TOKENS_PER_POLL                 = judgeAPI.MAX_BATCH_SIZE
+	MAX_BATCH_SIZE                      = judgeAPI.MAX_BATCH_SIZE
+
+	// jobs live in the store, a claimed job is ours until its lease runs out
+	JOB_LEASE             = 60 // seconds
+	PERIOD_BETWEEN_CLAIMS = 1  // seconds
+	// jobs the judge rejected this many times go to the dead letters
+	MAX_JUDGE_POST_ATTEMPTS = 5
+	// a chunk failing after others were accepted is sent again on its own
+	CHUNK_POST_ATTEMPTS        = 3
+	CHUNK_POST_RETRY_DELAY     = 1 // seconds
+	MAX_CHUNK_POST_RETRY_DELAY = 5 // seconds
+
+	// past this many queued jobs new submissions are turned away
+	QUEUE_CAPACITY         = 1000
+	QUEUE_FULL_RETRY_AFTER = 30 // seconds
+
+	// the scheduler looks at the next job of this many users at once
+	SCHEDULING_WINDOW      = 100
+	MAX_IN_FLIGHT_PER_USER = 3
+
+	// with callbacks polling is only a fallback for callbacks that never came
+	CALLBACK_FALLBACK_DELAY        = 30 // seconds
+	CALLBACK_FALLBACK_CHECKS_COUNT = 20
+	MAX_CALLBACK_FALLBACK_DELAY    = 5 * 60 // seconds
+	CALLBACK_FALLBACK_BACKOFF      = 1.5
+
+	// stop on first failure problems send this many tests at a time, the
+	// next wave only goes out once every test of the last one passed
+	STOP_ON_FIRST_FAILURE_WAVE_SIZE = 5
 )
 
 type SubmissionJob struct {
 	SubmissionID uuid.UUID
+	UserID       uuid.UUID
 	Language     judgeAPI.JudgeLanguage
 	Code         string
-	Timelimit    float64
-	MemoryLimit  float64
-	ProblemID    uuid.UUID
-	Testcases    []models.Testcase
+	// the other files of multi-file submissions, Code is EntryPoint
+	Files       map[string][]byte
+	EntryPoint  string
+	Timelimit   float64
+	MemoryLimit float64
+	ProblemID   uuid.UUID
+	Testcases   []models.Testcase
+	JudgingMode models.JudgingMode
+	TestGroups  []models.TestGroup
+	ScoringMode models.ScoringMode
+	Comparison  Comparison
+	// nil when the output is matched by Comparison
+	Checker *Checker
+	// set for interactive problems, the interactor decides the verdict
+	Interactor *judgeAPI.Program
+	// set for function problems, Code is only the function
+	Harness *models.Harness
+	// set for output only problems, the uploaded output of every test
+	Outputs []string
+
+	// set when the job was already sent to the judge before, so
+	// polling can resume with the same tokens
+	Tokens   []string
+	Attempts int
 }
 
 type ResultTokens struct {
 	SubmissionID uuid.UUID
 	Tokens       []string
+	Testcases    []models.Testcase
+	Job          *SubmissionJob
+	// results of the earlier waves, their tokens lead Tokens
+	Finished []judgeAPI.Submission
+	// set instead of Tokens while the checker judges these runs, runs
+	// that need no check have no checker token
+	Checking      []judgeAPI.Submission
+	CheckerTokens []string
 }
 
 type DBUpdate struct {
 	SubmissionID uuid.UUID
 	Results      []judgeAPI.Submission
+	Job          *SubmissionJob
+	// how many of the leading results were compared or checked already
+	Checked int
+	// the judge never finished, Results only has the tests it did finish
+	TimedOut bool
+}
+
+// Verdict is what a submission ends with, FailedTest is the number of the
+// first test that was not accepted, 0 when there is none. Score is nil for
+// problems without test groups
+type Verdict struct {
+	Status     models.SubmissionStatus
+	FailedTest int
+	Score      *float64
 }
 
 type TestCaseResult struct {
@@ -56,150 +129,590 @@ type TestCaseResult struct {
 	JudgeResponse  []byte
 }
 
+type PipelineConfig struct {
+	SubmissionsProcessors int
+	DBWriters             int
+	ChannelsBuffer        int
+	CheckPeriod           time.Duration
+	MaxTokensPerPoll      int
+	MaxBatchSize          int
+	JudgeRetry            RetryPolicy
+	JobLease              time.Duration
+	ClaimPeriod           time.Duration
+	MaxPostAttempts       int
+	ChunkRetry            RetryPolicy
+	QueueCapacity         int
+	QueueFullRetryAfter   time.Duration
+	SchedulingWindow      int
+	MaxInFlightPerUser    int
+	WaveSize              int
+
+	// when CallbackURL is set judge zero PUTs every finished token to it,
+	// and polling with CallbackRetry only catches missed callbacks
+	CallbackURL   string
+	CallbackRetry RetryPolicy
+}
+
+func DefaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{
+		SubmissionsProcessors: SUBMISSIONS_PROCESSORS_COUNT,
+		DBWriters:             DB_WRITER_COUNT,
+		ChannelsBuffer:        CHANNELS_BUFFER,
+		CheckPeriod:           PERIOD_BETWEEN_EACH_JUDGE_API_CHECK * time.Second,
+		MaxTokensPerPoll:      MAX_TOKENS_PER_POLL,
+		MaxBatchSize:          MAX_BATCH_SIZE,
+		JudgeRetry: RetryPolicy{
+			MaxAttempts:  CHECK_WITH_JUDGEAPI_COUNT,
+			InitialDelay: PERIOD_BETWEEN_EACH_JUDGE_API_CHECK * time.Second,
+			MaxDelay:     MAX_PERIOD_BETWEEN_JUDGE_API_CHECKS * time.Second,
+			Multiplier:   JUDGE_API_CHECKS_BACKOFF,
+		},
+		JobLease:        JOB_LEASE * time.Second,
+		ClaimPeriod:     PERIOD_BETWEEN_CLAIMS * time.Second,
+		MaxPostAttempts: MAX_JUDGE_POST_ATTEMPTS,
+		ChunkRetry: RetryPolicy{
+			MaxAttempts:  CHUNK_POST_ATTEMPTS,
+			InitialDelay: CHUNK_POST_RETRY_DELAY * time.Second,
+			MaxDelay:     MAX_CHUNK_POST_RETRY_DELAY * time.Second,
+			Multiplier:   2,
+		},
+		QueueCapacity:       QUEUE_CAPACITY,
+		QueueFullRetryAfter: QUEUE_FULL_RETRY_AFTER * time.Second,
+		SchedulingWindow:    SCHEDULING_WINDOW,
+		MaxInFlightPerUser:  MAX_IN_FLIGHT_PER_USER,
+		WaveSize:            STOP_ON_FIRST_FAILURE_WAVE_SIZE,
+		CallbackRetry: RetryPolicy{
+			MaxAttempts:  CALLBACK_FALLBACK_CHECKS_COUNT,
+			InitialDelay: CALLBACK_FALLBACK_DELAY * time.Second,
+			MaxDelay:     MAX_CALLBACK_FALLBACK_DELAY * time.Second,
+			Multiplier:   CALLBACK_FALLBACK_BACKOFF,
+		},
+	}
+}
+
 type SubmissionsPipeline struct {
-	submissionsChan chan *SubmissionJob
-	resultsChan     chan *ResultTokens
-	dbUpdateChan    chan *DBUpdate
-	judgeAPI        *judgeAPI.JudgeAPI
-	db              *sql.DB
-	dbQueries       *database.Queries
-	wg              sync.WaitGroup
-	ctx             context.Context
-	cancel          context.CancelFunc
-}
-
-func NewSubmissionPipeline(judgeAPI *judgeAPI.JudgeAPI, db *sql.DB, dbQueries *database.Queries) *SubmissionsPipeline {
+	newJobs      chan struct{}
+	resultsChan  chan *ResultTokens
+	dbUpdateChan chan *DBUpdate
+	wavesChan    chan *DBUpdate
+	checksChan   chan *DBUpdate
+	judgeAPI     judgeAPI.Judge
+	store        Store
+	config       PipelineConfig
+	pending      *pendingTracker
+	scheduler    *scheduler
+	claimMu      sync.Mutex
+	recoveryMu   sync.Mutex
+	recovery     *RecoveryReport
+	wg           sync.WaitGroup
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+func NewSubmissionPipeline(judgeAPI judgeAPI.Judge, store Store, config PipelineConfig) *SubmissionsPipeline {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &SubmissionsPipeline{
-		submissionsChan: make(chan *SubmissionJob, CHANNELS_BUFFER),
-		resultsChan:     make(chan *ResultTokens, CHANNELS_BUFFER),
-		dbUpdateChan:    make(chan *DBUpdate, CHANNELS_BUFFER),
-		judgeAPI:        judgeAPI,
-		db:              db,
-		dbQueries:       dbQueries,
-		ctx:             ctx,
-		cancel:          cancel,
+		newJobs:      make(chan struct{}, config.ChannelsBuffer),
+		resultsChan:  make(chan *ResultTokens, config.ChannelsBuffer),
+		dbUpdateChan: make(chan *DBUpdate, config.ChannelsBuffer),
+		wavesChan:    make(chan *DBUpdate, config.ChannelsBuffer),
+		checksChan:   make(chan *DBUpdate, config.ChannelsBuffer),
+		judgeAPI:     judgeAPI,
+		store:        store,
+		config:       config,
+		pending:      newPendingTracker(),
+		scheduler:    newScheduler(config.MaxInFlightPerUser),
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 }
 
 func (sp *SubmissionsPipeline) Start() {
-	for range SUBMISSIONS_PROCESSORS_COUNT {
+	for range sp.config.SubmissionsProcessors {
 		sp.wg.Add(1)
 		go sp.submissionsProcessor()
 	}
 
-	for range RESULTS_PROCESSORS_COUNT {
-		sp.wg.Add(1)
-		go sp.resultsProcessor()
-	}
+	sp.wg.Add(1)
+	go sp.resultsProcessor()
 
-	for range DB_WRITER_COUNT {
+	for range sp.config.DBWriters {
 		sp.wg.Add(1)
 		go sp.databaseUpdater()
 	}
 
 }
 
-func (sp *SubmissionsPipeline) Submit(job *SubmissionJob) error {
+// Recover picks up the submissions a previous run left pending, it should be
+// called once before Start
+func (sp *SubmissionsPipeline) Recover() (RecoveryReport, error) {
+	report, err := sp.store.RecoverPendingSubmissions(sp.ctx)
+	if err != nil {
+		return report, err
+	}
+
+	sp.recoveryMu.Lock()
+	sp.recovery = &report
+	sp.recoveryMu.Unlock()
+
+	return report, nil
+}
+
+// LastRecovery is the report of the last Recover call, nil if it never ran
+func (sp *SubmissionsPipeline) LastRecovery() *RecoveryReport {
+	sp.recoveryMu.Lock()
+	defer sp.recoveryMu.Unlock()
+	return sp.recovery
+}
+
+type QueueStatus struct {
+	Depth    int `json:"depth"`
+	Capacity int `json:"capacity"`
+	// how long a turned away client should wait before submitting again
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (qs QueueStatus) Full() bool {
+	return qs.Depth >= qs.Capacity
+}
+
+// QueueStatus tells how full the judge queue is, check it before creating a
+// submission so a full queue does not leave submissions nobody will judge
+func (sp *SubmissionsPipeline) QueueStatus(ctx context.Context) (QueueStatus, error) {
+	depth, err := sp.store.QueueDepth(ctx)
+	if err != nil {
+		return QueueStatus{}, fmt.Errorf("error getting submission queue depth: %w", err)
+	}
+
+	return QueueStatus{
+		Depth:      depth,
+		Capacity:   sp.config.QueueCapacity,
+		RetryAfter: sp.config.QueueFullRetryAfter,
+	}, nil
+}
+
+// Submit persists the job in the store and wakes up a processor, the job is
+// claimed from the store so it is not lost if we go down before judging it.
+// it returns the job position in the queue
+func (sp *SubmissionsPipeline) Submit(job *SubmissionJob) (int, error) {
+	position, err := sp.store.EnqueueJob(sp.ctx, job)
+	if err != nil {
+		return 0, fmt.Errorf("error enqueuing submission job: %w", err)
+	}
+
+	sp.wakeProcessors()
+	return position, nil
+}
+
+// SubmitNew creates the submission with its job, so there is never a
+// submission row left pending without a job to judge it. it returns the
+// new submission id and its position in the queue
+func (sp *SubmissionsPipeline) SubmitNew(ctx context.Context, submission database.CreateSubmissionParams) (uuid.UUID, int, error) {
+	submissionID, position, err := sp.store.CreateSubmissionJob(ctx, submission)
+	if err != nil {
+		return uuid.Nil, 0, fmt.Errorf("error enqueuing submission job: %w", err)
+	}
+
+	sp.wakeProcessors()
+	return submissionID, position, nil
+}
+
+func (sp *SubmissionsPipeline) wakeProcessors() {
 	select {
-	case sp.submissionsChan <- job:
-		return nil
-	case <-time.After(WAIT_TILL_CHANNEL_BUFFER_OPENS * time.Second):
-		return fmt.Errorf("submission pipeline is full")
+	case sp.newJobs <- struct{}{}:
+	default:
+		// processors are already awake, they will claim it on their next pass
 	}
 }
 
 func (sp *SubmissionsPipeline) Shutdown() {
 	sp.cancel()
 	sp.wg.Wait()
-	close(sp.submissionsChan)
+	close(sp.newJobs)
 	close(sp.resultsChan)
 	close(sp.dbUpdateChan)
+	close(sp.wavesChan)
+	close(sp.checksChan)
 }
 
 func (sp *SubmissionsPipeline) submissionsProcessor() {
 	defer sp.wg.Done()
 
+	ticker := time.NewTicker(sp.config.ClaimPeriod)
+	defer ticker.Stop()
+
 	for {
 		select {
+		case <-sp.newJobs:
+		case <-ticker.C:
+		case update := <-sp.wavesChan:
+			sp.judgeNextWave(update)
+			continue
+		case update := <-sp.checksChan:
+			sp.checkRuns(update)
+			continue
+		case <-sp.ctx.Done():
+			return
+		}
+
+		sp.claimQueuedJobs()
+	}
+}
+
+func (sp *SubmissionsPipeline) claimQueuedJobs() {
+	for sp.ctx.Err() == nil {
+		job, err := sp.claimNextJob()
+		if err != nil {
+			log.Printf("error claiming submission job: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+
+		sp.judgeJob(job)
+	}
+}
 
-		case job := <-sp.submissionsChan:
-			submission := judgeAPI.NewSubmission(job.Code, job.Language)
-			submission.SetCPUTimeLimit(job.Timelimit)
-			submission.SetMemoryLimit(job.MemoryLimit)
-			batch := submission.GenerateBatchFromTestCases(job.Testcases...)
+// claimNextJob claims the job the scheduler picks, claims are serialized so
+// the in flight counts it looks at stay right between processors
+func (sp *SubmissionsPipeline) claimNextJob() (*SubmissionJob, error) {
+	sp.claimMu.Lock()
+	defer sp.claimMu.Unlock()
+
+	candidates, err := sp.store.QueuedJobs(sp.ctx, sp.config.SchedulingWindow)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	inFlight, err := sp.store.InFlightJobs(sp.ctx)
+	if err != nil {
+		return nil, err
+	}
 
-			tokens, err := sp.judgeAPI.PostBatchSubmission(batch)
+	for _, candidate := range sp.scheduler.order(candidates, inFlight) {
+		job, err := sp.store.ClaimJob(sp.ctx, candidate.SubmissionID, sp.config.JobLease)
+		if sp.deadLetterUnloadable(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			sp.scheduler.served(candidate.UserID)
+			return job, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (sp *SubmissionsPipeline) judgeJob(job *SubmissionJob) {
+	if job.Outputs != nil {
+		sp.judgeOutputs(job)
+		return
+	}
+
+	testcases := job.Testcases
+	if job.JudgingMode == models.STOP_ON_FIRST_FAILURE_JUDGING_MODE {
+		testcases = testcases[:min(sp.config.WaveSize, len(testcases))]
+	}
+
+	batch, err := sp.newBatch(job, testcases)
+	if err != nil {
+		log.Printf("Error making the batch of submission %v: %v", job.SubmissionID, err)
+		sp.failJob(job, err)
+		return
+	}
+
+	tokens, err := sp.postBatch(batch)
+	if err != nil {
+		log.Printf("Error submitting to judge0: %v", err)
+		sp.failJob(job, err)
+		return
+	}
+
+	sp.submitted(job, tokens, nil)
+}
+
+// judgeNextWave sends the tests after the ones update finished, if posting
+// fails the job is left to be claimed as stalled and tries this wave again
+func (sp *SubmissionsPipeline) judgeNextWave(update *DBUpdate) {
+	job := update.Job
+	from := len(update.Results)
+	testcases := job.Testcases[from:min(from+sp.config.WaveSize, len(job.Testcases))]
+
+	batch, err := sp.newBatch(job, testcases)
+	if err != nil {
+		log.Printf("Error making the batch of submission %v: %v", job.SubmissionID, err)
+		sp.failJob(job, err)
+		return
+	}
+
+	waveTokens, err := sp.postBatch(batch)
+	if err != nil {
+		log.Printf("Error submitting tests %d to %d of submission %v to judge0: %v", from+1, from+len(testcases), job.SubmissionID, err)
+		err = sp.store.RecordJobError(sp.ctx, job.SubmissionID, err, "")
+		if err != nil {
+			log.Printf("error recording submission %v job error: %v", job.SubmissionID, err)
+		}
+		return
+	}
+
+	tokens := make([]string, 0, from+len(waveTokens))
+	for _, result := range update.Results {
+		tokens = append(tokens, result.Token)
+	}
+	tokens = append(tokens, waveTokens...)
+
+	sp.submitted(job, tokens, update.Results)
+}
+
+// checkRuns sends the runs update has not checked yet to the job checker,
+// runs that did not even finish cleanly keep their status. checker tokens
+// are not stored, a job picked up as stalled polls its runs and checks again
+func (sp *SubmissionsPipeline) checkRuns(update *DBUpdate) {
+	job := update.Job
+	checking := update.Results[update.Checked:]
+
+	batch := judgeAPI.SubmissionBatch{}
+	checked := []int{}
+	for i, run := range checking {
+		if run.Status.ID != judgeAPI.ACCEPTED_STATUS {
+			continue
+		}
+
+		submission, err := job.Checker.submission(job.Testcases[update.Checked+i], run.Stdout)
+		if err != nil {
+			// the checker would fail the same way every time the job is picked up
+			sp.deadLetterUnloadable(&JobConfigError{SubmissionID: job.SubmissionID, Err: fmt.Errorf("error making checker submission: %w", err)})
+			return
+		}
+		if sp.config.CallbackURL != "" {
+			submission.SetCallbackURL(sp.config.CallbackURL)
+		}
+
+		batch = append(batch, submission)
+		checked = append(checked, i)
+	}
+
+	checkerTokens := make([]string, len(checking))
+	if len(batch) > 0 {
+		tokens, err := sp.postBatch(&batch)
+		if err != nil {
+			log.Printf("Error submitting checker runs of submission %v to judge0: %v", job.SubmissionID, err)
+			err = sp.store.RecordJobError(sp.ctx, job.SubmissionID, err, "")
 			if err != nil {
-				log.Printf("Error submitting to judge0: %v", err)
-				continue
+				log.Printf("error recording submission %v job error: %v", job.SubmissionID, err)
 			}
+			return
+		}
+
+		for k, i := range checked {
+			checkerTokens[i] = tokens[k]
+		}
+	}
+
+	resultTokens := ResultTokens{
+		SubmissionID:  job.SubmissionID,
+		Testcases:     job.Testcases,
+		Job:           job,
+		Finished:      update.Results[:update.Checked],
+		Checking:      checking,
+		CheckerTokens: checkerTokens,
+	}
+
+	select {
+	case sp.resultsChan <- &resultTokens:
+	case <-sp.ctx.Done():
+	}
+}
+
+// submitted saves the tokens the job has at the judge so far and starts
+// tracking the ones without results
+func (sp *SubmissionsPipeline) submitted(job *SubmissionJob, tokens []string, finished []judgeAPI.Submission) {
+	err := sp.store.MarkJobSubmitted(sp.ctx, job.SubmissionID, tokens, sp.config.JobLease)
+	if err != nil {
+		log.Printf("error saving submission %v judge tokens: %v", job.SubmissionID, err)
+	}
+
+	resultTokens := ResultTokens{
+		SubmissionID: job.SubmissionID,
+		Tokens:       tokens,
+		Testcases:    job.Testcases,
+		Job:          job,
+		Finished:     finished,
+	}
+
+	select {
+	case sp.resultsChan <- &resultTokens:
+	case <-sp.ctx.Done():
+	}
+}
+
+func (sp *SubmissionsPipeline) newBatch(job *SubmissionJob, testcases []models.Testcase) (*judgeAPI.SubmissionBatch, error) {
+	code := job.Code
+	if job.Harness != nil {
+		code = job.Harness.Wrap(code)
+	}
+
+	program := judgeAPI.Program{Language: job.Language, Code: code, Files: job.Files, Entry: job.EntryPoint}
+	var submission *judgeAPI.Submission
+	var err error
+	if job.Interactor != nil {
+		submission, err = judgeAPI.NewInteractiveSubmission(program, *job.Interactor)
+	} else {
+		submission, err = judgeAPI.NewProgramSubmission(program)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := sp.store.LanguageSettings(sp.ctx, job.Language)
+	if err != nil {
+		return nil, err
+	}
+	submission.SetCPUTimeLimit(settings.TimeLimit(job.Timelimit))
+	submission.SetMemoryLimit(settings.MemoryLimit(job.MemoryLimit))
+	// multi-file programs are built by their scripts, not by judge zero
+	if settings.CompilerOptions != "" && submission.LanguageID == int(job.Language) {
+		submission.SetCompilerOptions(settings.CompilerOptions)
+	}
+	if sp.config.CallbackURL != "" {
+		submission.SetCallbackURL(sp.config.CallbackURL)
+	}
+	batch := submission.GenerateBatchFromTestCases(testcases...)
+
+	// outputs are compared by us or the checker, judge zero only runs the code
+	for i := range *batch {
+		(*batch)[i].SetExpectedOutput("")
+	}
+
+	return batch, nil
+}
 
-			resultTokens := ResultTokens{
-				SubmissionID: job.SubmissionID,
-				Tokens:       tokens,
+// postBatch sends the batch in chunks, once a chunk was accepted a failing
+// one is sent again on its own so the tokens we already have are not thrown
+// away with the job
+func (sp *SubmissionsPipeline) postBatch(batch *judgeAPI.SubmissionBatch) ([]string, error) {
+	tokens := make([]string, 0, len(*batch))
+	for i, chunk := range batch.Chunks(sp.config.MaxBatchSize) {
+		chunkTokens, err := sp.postChunk(chunk)
+		for attempt := 0; err != nil && len(tokens) > 0 && attempt+1 < sp.config.ChunkRetry.MaxAttempts; attempt++ {
+			log.Printf("Error posting chunk %d of the batch, sending it again: %v", i+1, err)
+			select {
+			case <-time.After(sp.config.ChunkRetry.Delay(attempt)):
+			case <-sp.ctx.Done():
+				return nil, sp.ctx.Err()
 			}
+			chunkTokens, err = sp.postChunk(chunk)
+		}
+		if err != nil {
+			if len(tokens) > 0 {
+				log.Printf("Giving up on chunk %d of the batch, %d judge tokens are left behind", i+1, len(tokens))
+			}
+			return nil, fmt.Errorf("error posting chunk %d of the batch: %w", i+1, err)
+		}
+		tokens = append(tokens, chunkTokens...)
+	}
+	return tokens, nil
+}
+
+func (sp *SubmissionsPipeline) postChunk(chunk *judgeAPI.SubmissionBatch) ([]string, error) {
+	tokens, err := sp.judgeAPI.PostBatchSubmission(chunk)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) != len(*chunk) {
+		return nil, fmt.Errorf("judge returned %d tokens for %d submissions", len(tokens), len(*chunk))
+	}
+	return tokens, nil
+}
 
-			sp.resultsChan <- &resultTokens
+// failJob keeps a job the judge rejected queued, it is claimed again once
+// its lease runs out, until it runs out of attempts and becomes a dead letter
+func (sp *SubmissionsPipeline) failJob(job *SubmissionJob, jobErr error) {
+	response := ""
+	responseErr := &judgeAPI.ResponseError{}
+	if errors.As(jobErr, &responseErr) {
+		response = responseErr.Body
+	}
 
-		case <-sp.ctx.Done():
-			return
+	if job.Attempts < sp.config.MaxPostAttempts {
+		err := sp.store.RecordJobError(sp.ctx, job.SubmissionID, jobErr, response)
+		if err != nil {
+			log.Printf("error recording submission %v job error: %v", job.SubmissionID, err)
 		}
+		return
 	}
 
+	log.Printf("Submission %v was rejected %d times, moving it to dead letters", job.SubmissionID, job.Attempts)
+	err := sp.store.DeadLetterJob(sp.ctx, job.SubmissionID, jobErr, response)
+	if err != nil {
+		log.Printf("error moving submission %v job to dead letters: %v", job.SubmissionID, err)
+	}
+}
+
+// deadLetterUnloadable moves a job that failed with a JobConfigError straight
+// to the dead letters, it reports if err was one
+func (sp *SubmissionsPipeline) deadLetterUnloadable(err error) bool {
+	configErr := &JobConfigError{}
+	if !errors.As(err, &configErr) {
+		return false
+	}
+
+	log.Printf("Submission %v can not be judged, moving it to dead letters: %v", configErr.SubmissionID, configErr.Err)
+	err = sp.store.DeadLetterJob(sp.ctx, configErr.SubmissionID, configErr.Err, "")
+	if err != nil {
+		log.Printf("error moving submission %v job to dead letters: %v", configErr.SubmissionID, err)
+	}
+	return true
+}
+
+// RetryDeadJob puts a dead letter back in the queue with fresh attempts
+func (sp *SubmissionsPipeline) RetryDeadJob(ctx context.Context, submissionID uuid.UUID) error {
+	err := sp.store.RequeueDeadJob(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case sp.newJobs <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// FailDeadJob gives up on a dead letter, the submission ends as a server error
+func (sp *SubmissionsPipeline) FailDeadJob(ctx context.Context, submissionID uuid.UUID) error {
+	verdict := Verdict{Status: models.SERVER_ERROR_SUBMISSION_STATUS}
+	return sp.store.SaveResults(ctx, submissionID, verdict, []judgeAPI.Submission{}, nil)
 }
 
 func (sp *SubmissionsPipeline) resultsProcessor() {
 	defer sp.wg.Done()
 
-	ticker := time.NewTicker(PERIOD_BETWEEN_EACH_JUDGE_API_CHECK * time.Second)
+	ticker := time.NewTicker(sp.config.CheckPeriod)
 	defer ticker.Stop()
 
-	type pendingSubmission struct {
-		submissionID uuid.UUID
-		tokens       []string
-		checkCount   int
-	}
-	pendingSubmissions := make(map[uuid.UUID]pendingSubmission)
+	leaseTicker := time.NewTicker(sp.config.JobLease / 3)
+	defer leaseTicker.Stop()
+
+	sp.claimStalledJobs()
 
 	for {
 		select {
 		case result := <-sp.resultsChan:
-			pendingSubmissions[result.SubmissionID] = pendingSubmission{
-				submissionID: result.SubmissionID,
-				tokens:       result.Tokens,
-				checkCount:   0,
-			}
+			sp.track(result)
 
 		case <-ticker.C:
-			for id, pending := range pendingSubmissions {
-				if pending.checkCount >= CHECK_WITH_JUDGEAPI_COUNT {
-					log.Printf("Submission %v timed out after %d checks", id, CHECK_WITH_JUDGEAPI_COUNT)
-					delete(pendingSubmissions, id)
-					continue
-				}
-
-				submissions, err := sp.judgeAPI.GetBatchSubmissionsResult(pending.tokens)
-				if err != nil {
-					log.Printf("Error getting submissions from judge api: %v", err)
-					continue
-				}
-
-				allDone := areAllSubmissionsDone(submissions)
-
-				if allDone {
-					dpUpdate := DBUpdate{
-						SubmissionID: pending.submissionID,
-						Results:      submissions,
-					}
-					sp.dbUpdateChan <- &dpUpdate
-					delete(pendingSubmissions, id)
-				} else {
-					pending.checkCount += 1
-					pendingSubmissions[id] = pending
-				}
-			}
+			sp.checkPendingSubmissions()
+
+		case <-leaseTicker.C:
+			sp.renewLeases()
+			sp.claimStalledJobs()
 
 		case <-sp.ctx.Done():
 			return
@@ -207,57 +720,192 @@ func (sp *SubmissionsPipeline) resultsProcessor() {
 	}
 }
 
-func (sp *SubmissionsPipeline) databaseUpdater() {
-	defer sp.wg.Done()
+func (sp *SubmissionsPipeline) track(result *ResultTokens) {
+	update, done := sp.pending.add(result, time.Now().Add(sp.retryPolicy().Delay(0)))
+	if done {
+		sp.complete(update)
+	}
+}
 
-	for {
-		select {
-		case update := <-sp.dbUpdateChan:
+// complete hands on a submission whose tokens all finished, runs are compared
+// with the expected output here unless the job has a checker, then they go to
+// the checker first. a stop on first failure job that passed everything so far
+// goes back for its next wave
+func (sp *SubmissionsPipeline) complete(update *DBUpdate) error {
+	switch {
+	case update.Job == nil:
+	case update.Job.Interactor != nil:
+		applyInteractor(update)
+	case update.Job.Checker == nil:
+		update.Job.Comparison.compare(update)
+	}
 
-			tx, err := sp.db.Begin()
-			if err != nil {
-				log.Printf("Error starting transaction: %v", err)
-				continue
-			}
+	next := sp.dbUpdateChan
+	switch {
+	case needsCheck(update):
+		next = sp.checksChan
+	case needsNextWave(update):
+		next = sp.wavesChan
+	}
 
-			txq := sp.dbQueries.WithTx(tx)
-
-			status := calculateSubmissionStatus(update.Results)
-
-			tokens, stdins, stdouts, expectedOuts, statuses, times, memories, responses := getResultColumns(update.Results)
-			createResultsParams := database.CreateSubmissionResultsParams{
-				SubmissionID:    update.SubmissionID,
-				Tokens:          tokens,
-				Stdins:          stdins,
-				Stdouts:         stdouts,
-				Expectedoutputs: expectedOuts,
-				Statuses:        statuses,
-				Times:           times,
-				Memories:        memories,
-				Responses:       responses,
+	select {
+	case next <- update:
+		return nil
+	case <-sp.ctx.Done():
+		return fmt.Errorf("submission pipeline is shutting down")
+	}
+}
+
+func needsCheck(update *DBUpdate) bool {
+	return !update.TimedOut && update.Job != nil && update.Job.Checker != nil && update.Checked < len(update.Results)
+}
+
+func needsNextWave(update *DBUpdate) bool {
+	job := update.Job
+	if update.TimedOut || job == nil || job.JudgingMode != models.STOP_ON_FIRST_FAILURE_JUDGING_MODE {
+		return false
+	}
+	return len(update.Results) < len(job.Testcases) && firstFailedTest(update.Results) == 0
+}
+
+// renewLeases keeps the jobs we are still polling for from being claimed by
+// another instance
+func (sp *SubmissionsPipeline) renewLeases() {
+	ids := sp.pending.ids()
+	if len(ids) == 0 {
+		return
+	}
+
+	err := sp.store.RenewLeases(sp.ctx, ids, sp.config.JobLease)
+	if err != nil {
+		log.Printf("error renewing submission job leases: %v", err)
+	}
+}
+
+// claimStalledJobs picks up jobs that were sent to the judge by an instance
+// that stopped polling them, they are polled with their stored tokens
+func (sp *SubmissionsPipeline) claimStalledJobs() {
+	jobs, err := sp.store.ClaimStalledJobs(sp.ctx, sp.config.JobLease)
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, jobErr := range joined.Unwrap() {
+			if !sp.deadLetterUnloadable(jobErr) {
+				log.Printf("error loading stalled submission job: %v", jobErr)
 			}
-			_, err = txq.CreateSubmissionResults(sp.ctx, createResultsParams)
-			if err != nil {
-				log.Printf("error creating submission results: %v", err)
-				tx.Rollback()
-				continue
+		}
+	} else if err != nil {
+		log.Printf("error claiming stalled submission jobs: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if job.Outputs != nil {
+			sp.judgeOutputs(job)
+			continue
+		}
+
+		sp.track(&ResultTokens{
+			SubmissionID: job.SubmissionID,
+			Tokens:       job.Tokens,
+			Testcases:    job.Testcases,
+			Job:          job,
+		})
+	}
+}
+
+// checkPendingSubmissions polls every due token in as few batch requests as
+// judge zero allows and fans the results back out to their submissions
+func (sp *SubmissionsPipeline) checkPendingSubmissions() {
+	due := sp.pending.due(time.Now())
+
+	tokens := []string{}
+	owners := make(map[string]uuid.UUID)
+	for id, submissionTokens := range due {
+		for _, token := range submissionTokens {
+			tokens = append(tokens, token)
+			owners[token] = id
+		}
+	}
+
+	failed := make(map[uuid.UUID]bool)
+	for start := 0; start < len(tokens); start += sp.config.MaxTokensPerPoll {
+		chunk := tokens[start:min(start+sp.config.MaxTokensPerPoll, len(tokens))]
+
+		submissions, err := sp.judgeAPI.GetBatchSubmissionsResult(chunk)
+		if err != nil {
+			log.Printf("Error getting submissions from judge api: %v", err)
+			// these submissions are polled again next tick without counting a check
+			for _, token := range chunk {
+				failed[owners[token]] = true
 			}
+			continue
+		}
 
-			updateSubmissionStatus := database.UpdateSubmissionStatusParams{
-				ID:     update.SubmissionID,
-				Status: string(status),
+		for _, s := range submissions {
+			update, done := sp.pending.record(s)
+			if done {
+				sp.complete(update)
 			}
-			_, err = txq.UpdateSubmissionStatus(sp.ctx, updateSubmissionStatus)
-			if err != nil {
-				log.Printf("error updating submission status: %v", err)
-				tx.Rollback()
+		}
+	}
+
+	policy := sp.retryPolicy()
+	for id := range due {
+		if failed[id] {
+			continue
+		}
+
+		// finished submissions are no longer tracked and count 0
+		checkCount := sp.pending.checked(id, policy)
+		if checkCount > 0 && checkCount >= policy.MaxAttempts {
+			log.Printf("Submission %v timed out after %d checks", id, checkCount)
+			update, ok := sp.pending.timeout(id)
+			if !ok {
 				continue
 			}
+			select {
+			case sp.dbUpdateChan <- update:
+			case <-sp.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// HandleJudgeCallback takes a result judge zero PUT to our callback url
+func (sp *SubmissionsPipeline) HandleJudgeCallback(result judgeAPI.Submission) error {
+	if result.Token == "" {
+		return fmt.Errorf("judge callback without a token")
+	}
+
+	update, done := sp.pending.record(result)
+	if !done {
+		return nil
+	}
+
+	return sp.complete(update)
+}
 
-			err = tx.Commit()
+func (sp *SubmissionsPipeline) retryPolicy() RetryPolicy {
+	if sp.config.CallbackURL != "" {
+		return sp.config.CallbackRetry
+	}
+	return sp.config.JudgeRetry
+}
+
+func (sp *SubmissionsPipeline) databaseUpdater() {
+	defer sp.wg.Done()
+
+	for {
+		select {
+		case update := <-sp.dbUpdateChan:
+			verdict, results := judgeVerdict(update)
+			var testcases []models.Testcase
+			if update.Job != nil {
+				testcases = update.Job.Testcases
+			}
+			err := sp.store.SaveResults(sp.ctx, update.SubmissionID, verdict, results, testcases)
 			if err != nil {
-				log.Printf("errror commitng transaction to add submissions resluts and update status: %v", err)
-				tx.Rollback()
+				log.Printf("error saving submission %v results: %v", update.SubmissionID, err)
 			}
 
 		case <-sp.ctx.Done():
@@ -266,39 +914,64 @@ func (sp *SubmissionsPipeline) databaseUpdater() {
 	}
 }
 
-func getResultColumns(results []judgeAPI.Submission) (
-	tokens []string,
-	stdins []string,
-	stdouts []string,
-	expectedOutputs []string,
-	statuses []int32,
-	timeUsed []string,
-	memoryUsed []float64,
-	judgeResponses [][]byte,
-) {
-	n := len(results)
-	tokens = make([]string, n)
-	stdins = make([]string, n)
-	stdouts = make([]string, n)
-	expectedOutputs = make([]string, n)
-	statuses = make([]int32, n)
-	timeUsed = make([]string, n)
-	memoryUsed = make([]float64, n)
-	judgeResponses = make([][]byte, n)
+// judgeVerdict also returns the results worth keeping, with stop on first
+// failure the tests after the failed one count as never run
+func judgeVerdict(update *DBUpdate) (Verdict, []judgeAPI.Submission) {
+	if update.TimedOut {
+		return Verdict{Status: models.JUDGE_TIMEOUT_SUBMISSION_STATUS}, update.Results
+	}
 
-	for i, result := range results {
-		tokens[i] = result.Token
-		stdins[i] = result.Stdin
-		stdouts[i] = result.Stdout
-		expectedOutputs[i] = result.ExpectedOutput
-		statuses[i] = int32(result.Status.ID)
-		timeUsed[i] = result.Time
-		memoryUsed[i] = result.Memory
-		judgeResponses[i] = result.JSON()
+	results := update.Results
+	failedTest := firstFailedTest(results)
+	stopOnFailure := update.Job != nil && update.Job.JudgingMode == models.STOP_ON_FIRST_FAILURE_JUDGING_MODE
+	if failedTest > 0 && stopOnFailure {
+		results = results[:failedTest]
+	}
+
+	verdict := Verdict{
+		Status:     calculateSubmissionStatus(results),
+		FailedTest: failedTest,
+	}
+	if !stopOnFailure {
+		// the status is the worst of all the tests, point at the test that got it
+		verdict.FailedTest = firstTestWithStatus(results, verdict.Status)
+	}
+	if verdict.Status == models.COMPILATION_ERROR_SUBMISSION_STATUS {
+		// it fails every test the same, no test is worth pointing at
+		verdict.FailedTest = 0
+	}
+
+	if update.Job != nil && len(update.Job.TestGroups) > 0 {
+		score := calculateScore(update.Job.Testcases, update.Job.TestGroups, update.Job.ScoringMode, results)
+		verdict.Score = &score
+	}
+
+	return verdict, results
+}
 
+// firstFailedTest is the number of the first result that was not accepted,
+// 0 when all of them were
+func firstFailedTest(results []judgeAPI.Submission) int {
+	for i, result := range results {
+		if result.Status.ID != judgeAPI.ACCEPTED_STATUS {
+			return i + 1
+		}
 	}
+	return 0
+}
 
-	return
+// firstTestWithStatus is the number of the first result that alone would
+// give the submission status, 0 when status is not a failure
+func firstTestWithStatus(results []judgeAPI.Submission, status models.SubmissionStatus) int {
+	if status == models.ACCEPTED_SUBMISSION_STATUS {
+		return 0
+	}
+	for i := range results {
+		if calculateSubmissionStatus(results[i:i+1]) == status {
+			return i + 1
+		}
+	}
+	return 0
 }
 
 func calculateSubmissionStatus(submissions []judgeAPI.Submission) models.SubmissionStatus {
@@ -324,7 +997,7 @@ func calculateSubmissionStatus(submissions []judgeAPI.Submission) models.Submiss
 	}
 
 	for _, s := range submissions {
-		if s.Status.ID == judgeAPI.RUNTIME_ERROR_NZEC_STATUS && s.Message == "Exited with error status 137" {
+		if s.Status.ID == judgeAPI.RUNTIME_ERROR_NZEC_STATUS && s.Message == judgeAPI.MEMORY_LIMIT_EXCEEDED_MESSAGE {
 			return models.MEMORY_LIMIT_SUBMISSION_STATUS
 		}
 	}
@@ -361,15 +1034,6 @@ func calculateSubmissionStatus(submissions []judgeAPI.Submission) models.Submiss
 	return models.ACCEPTED_SUBMISSION_STATUS
 }
 
-func areAllSubmissionsDone(submissions []judgeAPI.Submission) bool {
-	submissionsCount := len(submissions)
-	doneCount := 0
-
-	for _, s := range submissions {
-		if s.Status.ID != judgeAPI.IN_QUEUE_STATUS && s.Status.ID != judgeAPI.PROCESSING_STATUS {
-			doneCount += 1
-		}
-	}
-
-	return submissionsCount == doneCount
+func isSubmissionDone(s judgeAPI.Submission) bool {
+	return s.Status.ID != judgeAPI.IN_QUEUE_STATUS && s.Status.ID != judgeAPI.PROCESSING_STATUS
 }
@@ -0,0 +1,953 @@
+package submissionsPL
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI/judge0test"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func testPipelineConfig() PipelineConfig {
+	config := DefaultPipelineConfig()
+	config.CheckPeriod = 10 * time.Millisecond
+	config.JudgeRetry = RetryPolicy{MaxAttempts: 5, InitialDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond, Multiplier: 2}
+	config.ChunkRetry = RetryPolicy{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, Multiplier: 2}
+	return config
+}
+
+func startTestPipeline(t *testing.T, config PipelineConfig) (*SubmissionsPipeline, *judge0test.Server, *memStore) {
+	t.Helper()
+
+	server := judge0test.NewServer()
+	t.Cleanup(server.Close)
+
+	store := newMemStore()
+	return startPipelineWith(t, config, server, store), server, store
+}
+
+func startPipelineWith(t *testing.T, config PipelineConfig, server *judge0test.Server, store Store) *SubmissionsPipeline {
+	t.Helper()
+
+	sp := NewSubmissionPipeline(server.Judge(), store, config)
+	sp.Start()
+	t.Cleanup(sp.Shutdown)
+
+	return sp
+}
+
+func testJob(testcases ...models.Testcase) *SubmissionJob {
+	return &SubmissionJob{
+		SubmissionID: uuid.New(),
+		Language:     judgeAPI.PYTHON_3,
+		Code:         "print(input())",
+		Timelimit:    1,
+		MemoryLimit:  128000,
+		ProblemID:    uuid.New(),
+		Testcases:    testcases,
+	}
+}
+
+func waitForResults(t *testing.T, store *memStore) savedResults {
+	t.Helper()
+
+	select {
+	case saved := <-store.saved:
+		return saved
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pipeline did not save any results")
+		return savedResults{}
+	}
+}
+
+func expectNoResults(t *testing.T, store *memStore, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case saved := <-store.saved:
+		t.Fatalf("wanted no results, got %v for submission %v", saved.status, saved.submissionID)
+	case <-time.After(wait):
+	}
+}
+
+func TestPipelineAccepted(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+	server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+		verdict := judge0test.AcceptAll(s)
+		verdict.QueuedPolls = 1
+		verdict.ProcessingPolls = 1
+		return verdict
+	})
+
+	job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("5 6", "5 6"), *models.NewTestCase("9 1", "9 1"))
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.submissionID != job.SubmissionID {
+		t.Fatalf("got results for %v, wanted %v", saved.submissionID, job.SubmissionID)
+	}
+	if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+		t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+	}
+	if len(saved.results) != 3 {
+		t.Fatalf("got %d results, wanted 3", len(saved.results))
+	}
+	for i, tc := range job.Testcases {
+		if saved.results[i].Stdin != tc.Stdin {
+			t.Fatalf("result %d has stdin %q, wanted %q", i, saved.results[i].Stdin, tc.Stdin)
+		}
+	}
+
+	posted := server.Submissions()
+	if posted[0].CPUTimeLimit != "1" || posted[0].MemoryLimit != 128000 {
+		t.Fatalf("limits were not sent to judge, got cpu %q memory %v", posted[0].CPUTimeLimit, posted[0].MemoryLimit)
+	}
+}
+
+func TestPipelineSubmitNew(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+	server.SetVerdict(judge0test.AcceptAll)
+
+	problemID := uuid.New()
+	store.setProblemTests(problemID, *models.NewTestCase("1 2", "1 2"))
+
+	submission := database.CreateSubmissionParams{
+		ProblemID:  problemID,
+		UserID:     uuid.New(),
+		Language:   int32(judgeAPI.PYTHON_3),
+		SourceCode: "print(input())",
+	}
+	submissionID, _, err := sp.SubmitNew(context.Background(), submission)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+	if submissionID == uuid.Nil {
+		t.Fatalf("no submission id was returned")
+	}
+
+	saved := waitForResults(t, store)
+	if saved.submissionID != submissionID || saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+		t.Fatalf("got %v for %v, wanted accepted for %v", saved.status, saved.submissionID, submissionID)
+	}
+}
+
+func TestPipelineLargeTestSet(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+	testcases := []models.Testcase{}
+	for i := range 45 {
+		testcases = append(testcases, *models.NewTestCase(strconv.Itoa(i), strconv.Itoa(i)))
+	}
+	job := testJob(testcases...)
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+		t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+	}
+	if len(saved.results) != 45 {
+		t.Fatalf("got %d results, wanted 45", len(saved.results))
+	}
+	for i, result := range saved.results {
+		if result.Stdin != strconv.Itoa(i) {
+			t.Fatalf("result %d is out of order, got stdin %q", i, result.Stdin)
+		}
+	}
+	if posts := server.Requests(judge0test.POST_BATCH_SUBMISSION_ENDPOINT); posts != 3 {
+		t.Fatalf("batch was posted in %d requests, wanted 3", posts)
+	}
+}
+
+func TestPipelineVerdicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		verdict judge0test.Verdict
+		want    models.SubmissionStatus
+	}{
+		{"wrong answer", judge0test.Verdict{Status: judgeAPI.WRONG_ANSWER_STATUS}, models.WRONG_ANSWER_SUBMISSION_STATUS},
+		{"time limit", judge0test.Verdict{Status: judgeAPI.TIME_LIMIT_EXCEEDED_STATUS}, models.TIME_LIMIT_SUBMISSION_STATUS},
+		{"compilation error", judge0test.Verdict{Status: judgeAPI.COMPILATION_ERROR_STATUS}, models.COMPILATION_ERROR_SUBMISSION_STATUS},
+		{"runtime error", judge0test.Verdict{Status: judgeAPI.RUNTIME_ERROR_SIGSEGV_STATUS}, models.RUNTIME_ERROR_SUBMISSION_STATUS},
+		{"memory limit", judge0test.Verdict{Status: judgeAPI.RUNTIME_ERROR_NZEC_STATUS, Message: "Exited with error status 137"}, models.MEMORY_LIMIT_SUBMISSION_STATUS},
+		{"internal error", judge0test.Verdict{Status: judgeAPI.INTERNAL_ERROR_STATUS}, models.SERVER_ERROR_SUBMISSION_STATUS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+			// only the second test fails, the rest are accepted
+			server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+				if s.Stdin == "bad" {
+					return tt.verdict
+				}
+				return judge0test.AcceptAll(s)
+			})
+
+			job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("bad", "0"), *models.NewTestCase("9 1", "9 1"))
+			_, err := sp.Submit(job)
+			if err != nil {
+				t.Fatalf("error submitting job: %v", err)
+			}
+
+			saved := waitForResults(t, store)
+			if saved.status != tt.want {
+				t.Fatalf("got status %v, wanted %v", saved.status, tt.want)
+			}
+
+			wantFailedTest := 2
+			if tt.want == models.COMPILATION_ERROR_SUBMISSION_STATUS {
+				wantFailedTest = 0
+			}
+			if saved.failedTest != wantFailedTest {
+				t.Fatalf("got failed test %d, wanted %d", saved.failedTest, wantFailedTest)
+			}
+		})
+	}
+}
+
+func TestPipelineFailedTestMatchesStatus(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+	server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+		switch s.Stdin {
+		case "wrong":
+			return judge0test.Verdict{Status: judgeAPI.WRONG_ANSWER_STATUS}
+		case "slow":
+			return judge0test.Verdict{Status: judgeAPI.TIME_LIMIT_EXCEEDED_STATUS}
+		}
+		return judge0test.AcceptAll(s)
+	})
+
+	job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("wrong", "0"), *models.NewTestCase("slow", "0"))
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	// all the tests run, the time limit on test 3 is the verdict and not test 2
+	saved := waitForResults(t, store)
+	if saved.status != models.TIME_LIMIT_SUBMISSION_STATUS || saved.failedTest != 3 {
+		t.Fatalf("got status %v on test %d, wanted %v on test 3", saved.status, saved.failedTest, models.TIME_LIMIT_SUBMISSION_STATUS)
+	}
+}
+
+func TestPipelineJudgingTimeout(t *testing.T) {
+	config := testPipelineConfig()
+	config.JudgeRetry.MaxAttempts = 3
+
+	sp, server, store := startTestPipeline(t, config)
+	server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+		if s.Stdin == "slow" {
+			return judge0test.Verdict{Status: judgeAPI.ACCEPTED_STATUS, ProcessingPolls: 1000}
+		}
+		return judge0test.AcceptAll(s)
+	})
+
+	job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("slow", "0"))
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.JUDGE_TIMEOUT_SUBMISSION_STATUS {
+		t.Fatalf("got status %v, wanted %v", saved.status, models.JUDGE_TIMEOUT_SUBMISSION_STATUS)
+	}
+	if len(saved.results) != 1 || saved.results[0].Stdin != "1 2" {
+		t.Fatalf("wanted only the finished result to be saved, got %+v", saved.results)
+	}
+	if mj := store.job(job.SubmissionID); mj.state != JOB_TIMED_OUT {
+		t.Fatalf("got job in state %v, wanted %v", mj.state, JOB_TIMED_OUT)
+	}
+
+	polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT)
+	if polls != config.JudgeRetry.MaxAttempts {
+		t.Fatalf("judge was polled %d times, wanted %d", polls, config.JudgeRetry.MaxAttempts)
+	}
+}
+
+func TestPipelineJudgeFailures(t *testing.T) {
+	t.Run("polling recovers after judge errors", func(t *testing.T) {
+		sp, server, store := startTestPipeline(t, testPipelineConfig())
+		server.FailNext(judge0test.GET_BATCH_SUBMISSION_ENDPOINT, 500, `{"error": "boom"}`)
+		server.FailNext(judge0test.GET_BATCH_SUBMISSION_ENDPOINT, 429, `{"message": "too many requests"}`)
+		server.FailNext(judge0test.GET_BATCH_SUBMISSION_ENDPOINT, 200, `{"submissions": [`)
+
+		_, err := sp.Submit(testJob(*models.NewTestCase("1 2", "1 2")))
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		saved := waitForResults(t, store)
+		if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+		}
+		if polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT); polls != 4 {
+			t.Fatalf("judge was polled %d times, wanted 4", polls)
+		}
+	})
+
+	t.Run("rejected batch is not polled", func(t *testing.T) {
+		sp, server, store := startTestPipeline(t, testPipelineConfig())
+		server.FailNext(judge0test.POST_BATCH_SUBMISSION_ENDPOINT, 429, `{"message": "too many requests"}`)
+
+		_, err := sp.Submit(testJob(*models.NewTestCase("1 2", "1 2")))
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		expectNoResults(t, store, 200*time.Millisecond)
+		if polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT); polls != 0 {
+			t.Fatalf("judge was polled %d times, wanted 0", polls)
+		}
+	})
+}
+
+func TestPipelineChunkRetry(t *testing.T) {
+	config := testPipelineConfig()
+	config.MaxBatchSize = 1
+
+	sp, server, store := startTestPipeline(t, config)
+	server.FailAfter(judge0test.POST_BATCH_SUBMISSION_ENDPOINT, 1, 503, `{"error": "busy"}`)
+
+	job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("3 4", "3 4"))
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.ACCEPTED_SUBMISSION_STATUS || len(saved.results) != 2 {
+		t.Fatalf("got status %v with %d results, wanted accepted with 2", saved.status, len(saved.results))
+	}
+	// the first chunk is not sent again, only the one that failed
+	if posts := server.Requests(judge0test.POST_BATCH_SUBMISSION_ENDPOINT); posts != 3 {
+		t.Fatalf("batch was posted %d times, wanted 3", posts)
+	}
+	if posted := len(server.Submissions()); posted != 2 {
+		t.Fatalf("judge got %d submissions, wanted 2", posted)
+	}
+}
+
+func TestPipelineDurableJobs(t *testing.T) {
+	t.Run("rejected batch is retried once its lease runs out", func(t *testing.T) {
+		config := testPipelineConfig()
+		config.JobLease = 60 * time.Millisecond
+		config.ClaimPeriod = 10 * time.Millisecond
+
+		sp, server, store := startTestPipeline(t, config)
+		server.FailNext(judge0test.POST_BATCH_SUBMISSION_ENDPOINT, 429, `{"message": "too many requests"}`)
+
+		job := testJob(*models.NewTestCase("1 2", "1 2"))
+		_, err := sp.Submit(job)
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		saved := waitForResults(t, store)
+		if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+		}
+		if posts := server.Requests(judge0test.POST_BATCH_SUBMISSION_ENDPOINT); posts != 2 {
+			t.Fatalf("batch was posted %d times, wanted 2", posts)
+		}
+		if mj := store.job(job.SubmissionID); mj.job.Attempts != 2 || mj.state != JOB_DONE {
+			t.Fatalf("got job in state %v after %d attempts, wanted %v after 2", mj.state, mj.job.Attempts, JOB_DONE)
+		}
+	})
+
+	t.Run("jobs rejected too many times become dead letters", func(t *testing.T) {
+		config := testPipelineConfig()
+		config.JobLease = 30 * time.Millisecond
+		config.ClaimPeriod = 10 * time.Millisecond
+		config.MaxPostAttempts = 2
+
+		sp, server, store := startTestPipeline(t, config)
+		server.FailNext(judge0test.POST_BATCH_SUBMISSION_ENDPOINT, 429, `{"message": "too many requests"}`)
+		server.FailNext(judge0test.POST_BATCH_SUBMISSION_ENDPOINT, 503, `{"message": "try later"}`)
+
+		job := testJob(*models.NewTestCase("1 2", "1 2"))
+		_, err := sp.Submit(job)
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		expectNoResults(t, store, 200*time.Millisecond)
+		mj := store.job(job.SubmissionID)
+		if mj.state != JOB_DEAD || mj.lastResponse != `{"message": "try later"}` {
+			t.Fatalf("got job in state %v with response %q, wanted a dead letter with the last response", mj.state, mj.lastResponse)
+		}
+		if posts := server.Requests(judge0test.POST_BATCH_SUBMISSION_ENDPOINT); posts != 2 {
+			t.Fatalf("batch was posted %d times, wanted 2", posts)
+		}
+
+		err = sp.RetryDeadJob(context.Background(), job.SubmissionID)
+		if err != nil {
+			t.Fatalf("error retrying dead job: %v", err)
+		}
+
+		saved := waitForResults(t, store)
+		if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+		}
+	})
+
+	t.Run("jobs that can not be loaded become dead letters", func(t *testing.T) {
+		server := judge0test.NewServer()
+		t.Cleanup(server.Close)
+
+		store := newMemStore()
+		broken := func(job *SubmissionJob) {
+			store.update(job.SubmissionID, func(mj *memJob) { mj.loadErr = fmt.Errorf("interactive problem has no interactor") })
+		}
+
+		// a queued and a stalled job of a broken problem next to a stalled one
+		// that is fine, none of them should hold up the others
+		queued := testJob(*models.NewTestCase("1 2", "1 2"))
+		store.EnqueueJob(context.Background(), queued)
+		broken(queued)
+
+		stalled := testJob(*models.NewTestCase("1 2", "1 2"))
+		store.EnqueueJob(context.Background(), stalled)
+		store.MarkJobSubmitted(context.Background(), stalled.SubmissionID, []string{"lost"}, -time.Second)
+		broken(stalled)
+
+		job := testJob(*models.NewTestCase("1 2", "1 2"))
+		tokens, err := server.Judge().PostBatchSubmission(judgeAPI.NewSubmission(job.Code, job.Language).GenerateBatchFromTestCases(job.Testcases...))
+		if err != nil {
+			t.Fatalf("error posting batch: %v", err)
+		}
+		store.EnqueueJob(context.Background(), job)
+		store.MarkJobSubmitted(context.Background(), job.SubmissionID, tokens, -time.Second)
+
+		sp := startPipelineWith(t, testPipelineConfig(), server, store)
+
+		saved := waitForResults(t, store)
+		if saved.submissionID != job.SubmissionID || saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got %v for %v, wanted accepted for %v", saved.status, saved.submissionID, job.SubmissionID)
+		}
+
+		next := testJob(*models.NewTestCase("3 4", "3 4"))
+		_, err = sp.Submit(next)
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+		if saved := waitForResults(t, store); saved.submissionID != next.SubmissionID {
+			t.Fatalf("got results for %v, wanted %v", saved.submissionID, next.SubmissionID)
+		}
+
+		for _, dead := range []*SubmissionJob{queued, stalled} {
+			if mj := store.job(dead.SubmissionID); mj.state != JOB_DEAD || mj.lastError == "" {
+				t.Fatalf("got job in state %v with error %q, wanted a dead letter", mj.state, mj.lastError)
+			}
+		}
+	})
+
+	t.Run("submitted jobs resume polling with their stored tokens", func(t *testing.T) {
+		server := judge0test.NewServer()
+		t.Cleanup(server.Close)
+
+		// a job an instance that went down had already sent to the judge
+		job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("9 1", "9 1"))
+		batch := judgeAPI.NewSubmission(job.Code, job.Language).GenerateBatchFromTestCases(job.Testcases...)
+		tokens, err := server.Judge().PostBatchSubmission(batch)
+		if err != nil {
+			t.Fatalf("error posting batch: %v", err)
+		}
+
+		store := newMemStore()
+		store.EnqueueJob(context.Background(), job)
+		store.MarkJobSubmitted(context.Background(), job.SubmissionID, tokens, -time.Second)
+
+		startPipelineWith(t, testPipelineConfig(), server, store)
+
+		saved := waitForResults(t, store)
+		if saved.submissionID != job.SubmissionID || saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got %v for %v, wanted %v for %v", saved.status, saved.submissionID, models.ACCEPTED_SUBMISSION_STATUS, job.SubmissionID)
+		}
+		if saved.results[1].Stdin != "9 1" {
+			t.Fatalf("resumed results were not matched with test cases, got %+v", saved.results[1])
+		}
+		if posts := server.Requests(judge0test.POST_BATCH_SUBMISSION_ENDPOINT); posts != 1 {
+			t.Fatalf("batch was posted %d times, wanted 1", posts)
+		}
+	})
+}
+
+func startCallbackReceiver(t *testing.T, config *PipelineConfig, sp **SubmissionsPipeline) {
+	t.Helper()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := judgeAPI.Submission{}
+		err := json.NewDecoder(r.Body).Decode(&result)
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		err = result.DecodeCallback()
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+
+		err = (*sp).HandleJudgeCallback(result)
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+	}))
+	t.Cleanup(receiver.Close)
+
+	config.CallbackURL = receiver.URL + "/judge/callback"
+}
+
+func TestPipelineCallbacks(t *testing.T) {
+	t.Run("callbacks finish judging without polling", func(t *testing.T) {
+		var sp *SubmissionsPipeline
+		config := testPipelineConfig()
+		config.CallbackRetry.InitialDelay = time.Minute
+		startCallbackReceiver(t, &config, &sp)
+
+		sp, server, store := startTestPipeline(t, config)
+		server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+			if s.Stdin == "bad" {
+				return judge0test.Verdict{Status: judgeAPI.WRONG_ANSWER_STATUS, Stdout: "1"}
+			}
+			return judge0test.AcceptAll(s)
+		})
+
+		job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("bad", "0"))
+		_, err := sp.Submit(job)
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		saved := waitForResults(t, store)
+		if saved.status != models.WRONG_ANSWER_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.WRONG_ANSWER_SUBMISSION_STATUS)
+		}
+		if saved.results[1].Stdin != "bad" || saved.results[1].ExpectedOutput != "0" {
+			t.Fatalf("callback result was not matched with its test case, got %+v", saved.results[1])
+		}
+		if polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT); polls != 0 {
+			t.Fatalf("judge was polled %d times, wanted 0", polls)
+		}
+		if posted := server.Submissions(); posted[0].CallbackURL != config.CallbackURL {
+			t.Fatalf("callback url was not sent to judge, got %q", posted[0].CallbackURL)
+		}
+	})
+
+	t.Run("base64 callbacks of correct output are accepted", func(t *testing.T) {
+		var sp *SubmissionsPipeline
+		config := testPipelineConfig()
+		config.CallbackRetry.InitialDelay = time.Minute
+		startCallbackReceiver(t, &config, &sp)
+
+		sp, server, store := startTestPipeline(t, config)
+		_, err := sp.Submit(testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("3 4", "3 4")))
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		saved := waitForResults(t, store)
+		if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+		}
+		if saved.results[0].Stdout != "1 2" {
+			t.Fatalf("got stdout %q, wanted it decoded", saved.results[0].Stdout)
+		}
+		if polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT); polls != 0 {
+			t.Fatalf("judge was polled %d times, wanted 0", polls)
+		}
+	})
+
+	t.Run("missed callbacks are picked up by polling", func(t *testing.T) {
+		var sp *SubmissionsPipeline
+		config := testPipelineConfig()
+		config.CallbackRetry.InitialDelay = 50 * time.Millisecond
+		startCallbackReceiver(t, &config, &sp)
+
+		sp, server, store := startTestPipeline(t, config)
+		server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+			verdict := judge0test.AcceptAll(s)
+			verdict.DropCallback = s.Stdin == "lost"
+			return verdict
+		})
+
+		_, err := sp.Submit(testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("lost", "lost")))
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+
+		saved := waitForResults(t, store)
+		if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+		}
+		if polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT); polls != 1 {
+			t.Fatalf("judge was polled %d times, wanted 1", polls)
+		}
+	})
+}
+
+func TestPipelineQueueStatus(t *testing.T) {
+	config := testPipelineConfig()
+	config.SubmissionsProcessors = 0 // nothing leaves the queue
+	config.QueueCapacity = 2
+
+	sp, _, _ := startTestPipeline(t, config)
+
+	for want := 1; want <= 2; want++ {
+		position, err := sp.Submit(testJob(*models.NewTestCase("1 2", "1 2")))
+		if err != nil {
+			t.Fatalf("error submitting job: %v", err)
+		}
+		if position != want {
+			t.Fatalf("got queue position %d, wanted %d", position, want)
+		}
+	}
+
+	queue, err := sp.QueueStatus(context.Background())
+	if err != nil {
+		t.Fatalf("error getting queue status: %v", err)
+	}
+	if queue.Depth != 2 || !queue.Full() {
+		t.Fatalf("got queue depth %d of %d, wanted a full queue of 2", queue.Depth, queue.Capacity)
+	}
+}
+
+func TestPipelineCoalescedPolling(t *testing.T) {
+	server := judge0test.NewServer()
+	t.Cleanup(server.Close)
+
+	// three submissions an earlier run sent, all due on the same tick
+	store := newMemStore()
+	jobs := []*SubmissionJob{}
+	for range 3 {
+		job := testJob(*models.NewTestCase("1 2", "1 2"), *models.NewTestCase("9 1", "9 1"))
+		batch := judgeAPI.NewSubmission(job.Code, job.Language).GenerateBatchFromTestCases(job.Testcases...)
+		tokens, err := server.Judge().PostBatchSubmission(batch)
+		if err != nil {
+			t.Fatalf("error posting batch: %v", err)
+		}
+
+		store.EnqueueJob(context.Background(), job)
+		store.MarkJobSubmitted(context.Background(), job.SubmissionID, tokens, -time.Second)
+		jobs = append(jobs, job)
+	}
+
+	config := testPipelineConfig()
+	config.MaxTokensPerPoll = 4
+	startPipelineWith(t, config, server, store)
+
+	for range jobs {
+		saved := waitForResults(t, store)
+		if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+			t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+		}
+	}
+
+	// six tokens in batches of four
+	if polls := server.Requests(judge0test.GET_BATCH_SUBMISSION_ENDPOINT); polls != 2 {
+		t.Fatalf("judge was polled %d times, wanted 2", polls)
+	}
+	if fields := server.LastQuery(judge0test.GET_BATCH_SUBMISSION_ENDPOINT).Get("fields"); fields == "*" || fields == "" {
+		t.Fatalf("poll asked for fields %q, wanted only the stored ones", fields)
+	}
+}
+
+func TestPipelineStopOnFirstFailure(t *testing.T) {
+	config := testPipelineConfig()
+	config.WaveSize = 2
+	sp, server, store := startTestPipeline(t, config)
+
+	// tests 3 and 4 fail in the same wave, test 3 is the one that counts
+	server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+		switch s.Stdin {
+		case "3":
+			return judge0test.Verdict{Status: judgeAPI.WRONG_ANSWER_STATUS}
+		case "4":
+			return judge0test.Verdict{Status: judgeAPI.TIME_LIMIT_EXCEEDED_STATUS}
+		}
+		return judge0test.AcceptAll(s)
+	})
+
+	testcases := []models.Testcase{}
+	for i := range 10 {
+		testcases = append(testcases, *models.NewTestCase(strconv.Itoa(i+1), strconv.Itoa(i+1)))
+	}
+	job := testJob(testcases...)
+	job.JudgingMode = models.STOP_ON_FIRST_FAILURE_JUDGING_MODE
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.WRONG_ANSWER_SUBMISSION_STATUS {
+		t.Fatalf("got status %v, wanted %v", saved.status, models.WRONG_ANSWER_SUBMISSION_STATUS)
+	}
+	if saved.failedTest != 3 {
+		t.Fatalf("got failed test %d, wanted 3", saved.failedTest)
+	}
+	if len(saved.results) != 3 {
+		t.Fatalf("got %d results, wanted the 3 tests up to the failure", len(saved.results))
+	}
+
+	// two waves went out, nothing after the failing one
+	if posts := server.Requests(judge0test.POST_BATCH_SUBMISSION_ENDPOINT); posts != 2 {
+		t.Fatalf("judge got %d batches, wanted 2", posts)
+	}
+	if posted := len(server.Submissions()); posted != 4 {
+		t.Fatalf("judge got %d tests, wanted 4", posted)
+	}
+}
+
+func TestPipelineChecker(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+	// the checker takes any case, the participant prints in lower case
+	server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+		if s.AdditionalFiles == "" {
+			if s.ExpectedOutput != "" {
+				t.Errorf("run was sent with expected output %q, the checker should compare", s.ExpectedOutput)
+			}
+			return judge0test.Verdict{Status: judgeAPI.ACCEPTED_STATUS, Stdout: strings.ToLower(s.Stdin)}
+		}
+
+		files, err := judgeAPI.DecodeAdditionalFiles(s.AdditionalFiles)
+		if err != nil {
+			t.Errorf("error decoding checker files: %v", err)
+		}
+		if string(files["testlib.h"]) != "// testlib" || s.CommandLineArguments != "input.txt output.txt answer.txt" {
+			t.Errorf("checker was not given its files, got arguments %q", s.CommandLineArguments)
+		}
+		if strings.EqualFold(string(files[CHECKER_OUTPUT_FILE]), string(files[CHECKER_ANSWER_FILE])) {
+			return judge0test.Verdict{Status: judgeAPI.ACCEPTED_STATUS, Stderr: "ok answers match"}
+		}
+		return judge0test.Verdict{Status: judgeAPI.RUNTIME_ERROR_NZEC_STATUS, ExitCode: CHECKER_WRONG_ANSWER, Stderr: "wrong answer expected NO"}
+	})
+
+	job := testJob(*models.NewTestCase("YES", "YES"), *models.NewTestCase("MAYBE", "NO"))
+	job.Checker = &Checker{Language: judgeAPI.CPP_GCC_9, Code: "// checker", Files: map[string][]byte{"testlib.h": []byte("// testlib")}}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.WRONG_ANSWER_SUBMISSION_STATUS || saved.failedTest != 2 {
+		t.Fatalf("got status %v on test %d, wanted %v on test 2", saved.status, saved.failedTest, models.WRONG_ANSWER_SUBMISSION_STATUS)
+	}
+	if saved.results[0].Status.ID != judgeAPI.ACCEPTED_STATUS {
+		t.Fatalf("checker should have accepted %q, got %v", saved.results[0].Stdout, saved.results[0].Status)
+	}
+	if saved.results[1].Message != "wrong answer expected NO" {
+		t.Fatalf("got message %q, wanted the checker comment", saved.results[1].Message)
+	}
+	if saved.results[1].ExpectedOutput != "NO" {
+		t.Fatalf("got expected output %q, wanted the jury answer kept", saved.results[1].ExpectedOutput)
+	}
+}
+
+func TestPipelineBrokenChecker(t *testing.T) {
+	sp, _, store := startTestPipeline(t, testPipelineConfig())
+
+	// zip can not hold a file name this long, the checker can never be sent
+	job := testJob(*models.NewTestCase("YES", "YES"))
+	job.Checker = &Checker{Language: judgeAPI.CPP_GCC_9, Code: "// checker", Files: map[string][]byte{strings.Repeat("a", 1<<16): nil}}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	expectNoResults(t, store, 200*time.Millisecond)
+	if mj := store.job(job.SubmissionID); mj.state != JOB_DEAD {
+		t.Fatalf("got job in state %v, wanted a dead letter", mj.state)
+	}
+}
+
+func TestPipelineComparesOutputs(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+	job := testJob(*models.NewTestCase("yes", "YES"), *models.NewTestCase("2.0000001", "2"))
+	job.Comparison = Comparison{Mode: models.CASE_INSENSITIVE_COMPARISON_MODE}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.WRONG_ANSWER_SUBMISSION_STATUS || saved.failedTest != 2 {
+		t.Fatalf("got status %v on test %d, wanted %v on test 2", saved.status, saved.failedTest, models.WRONG_ANSWER_SUBMISSION_STATUS)
+	}
+	for _, s := range server.Submissions() {
+		if s.ExpectedOutput != "" {
+			t.Fatalf("expected output %q was sent to the judge", s.ExpectedOutput)
+		}
+	}
+
+	job = testJob(*models.NewTestCase("2.0000001", "2"))
+	job.Comparison = Comparison{Mode: models.FLOAT_COMPARISON_MODE, Epsilon: models.DEFAULT_COMPARISON_EPSILON}
+	_, err = sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved = waitForResults(t, store)
+	if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+		t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+	}
+}
+
+func TestPipelineInteractive(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+	server.SetVerdict(func(s judgeAPI.Submission) judge0test.Verdict {
+		if judgeAPI.JudgeLanguage(s.LanguageID) != judgeAPI.MULTI_FILE_PROGRAM || s.AdditionalFiles == "" {
+			t.Errorf("interactive run was not sent as a multi-file program")
+		}
+		if s.Stdin == "37" {
+			return judge0test.Verdict{Status: judgeAPI.ACCEPTED_STATUS, Stdout: "> 50\n< <\n> 37\n< =\n"}
+		}
+		return judge0test.Verdict{Status: judgeAPI.RUNTIME_ERROR_NZEC_STATUS, ExitCode: judgeAPI.INTERACTOR_WRONG_ANSWER, Stdout: "> 1\n< >\n", Stderr: "too many guesses"}
+	})
+
+	job := testJob(*models.NewTestCase("37", ""), *models.NewTestCase("99", ""))
+	job.Interactor = &judgeAPI.Program{Language: judgeAPI.PYTHON_3, Code: "# interactor"}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.WRONG_ANSWER_SUBMISSION_STATUS || saved.failedTest != 2 {
+		t.Fatalf("got status %v on test %d, wanted %v on test 2", saved.status, saved.failedTest, models.WRONG_ANSWER_SUBMISSION_STATUS)
+	}
+	if saved.results[0].QueryLog != "> 50\n< <\n> 37\n< =\n" || saved.results[0].Stdout != "50\n37\n" {
+		t.Fatalf("got query log %q and stdout %q", saved.results[0].QueryLog, saved.results[0].Stdout)
+	}
+	if saved.results[1].Message != "too many guesses" {
+		t.Fatalf("got message %q, wanted the interactor comment", saved.results[1].Message)
+	}
+}
+
+func TestPipelineFunctionHarness(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+	job := testJob(*models.NewTestCase("1 2", "1 2"))
+	job.Code = "def add(a, b):\n    return a + b\n"
+	job.Harness = &models.Harness{Template: "{{SOLUTION}}\nprint(add(*map(int, input().split())))\n"}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	waitForResults(t, store)
+	want := "def add(a, b):\n    return a + b\n\nprint(add(*map(int, input().split())))\n"
+	if posted := server.Submissions()[0].SourceCode; posted != want {
+		t.Fatalf("got source %q, wanted the function in its harness", posted)
+	}
+}
+
+func TestPipelineOutputOnly(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+	job := testJob(*models.NewTestCase("1 2", "3"), *models.NewTestCase("2 2", "4"))
+	job.Language = judgeAPI.PLAIN_TEXT
+	job.Code = ""
+	job.Outputs = []string{"3\n", "5"}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.WRONG_ANSWER_SUBMISSION_STATUS || saved.failedTest != 2 {
+		t.Fatalf("got status %v on test %d, wanted %v on test 2", saved.status, saved.failedTest, models.WRONG_ANSWER_SUBMISSION_STATUS)
+	}
+	if saved.results[0].Stdout != "3\n" {
+		t.Fatalf("got stdout %q, wanted the uploaded output", saved.results[0].Stdout)
+	}
+	if len(server.Submissions()) != 0 {
+		t.Fatalf("outputs were sent to the judge %d times", len(server.Submissions()))
+	}
+}
+
+func TestPipelineMultiFile(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+
+	job := testJob(*models.NewTestCase("1 2", "1 2"))
+	job.EntryPoint = "app.py"
+	job.Code = "from echo import echo\necho()\n"
+	job.Files = map[string][]byte{"echo.py": []byte("def echo():\n    print(input())\n")}
+	_, err := sp.Submit(job)
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+
+	saved := waitForResults(t, store)
+	if saved.status != models.ACCEPTED_SUBMISSION_STATUS {
+		t.Fatalf("got status %v, wanted %v", saved.status, models.ACCEPTED_SUBMISSION_STATUS)
+	}
+	for _, s := range server.Submissions() {
+		if judgeAPI.JudgeLanguage(s.LanguageID) != judgeAPI.MULTI_FILE_PROGRAM || s.AdditionalFiles == "" {
+			t.Fatalf("multi-file job was not sent as a multi-file program")
+		}
+	}
+}
+
+func TestPipelineLanguageSettings(t *testing.T) {
+	sp, server, store := startTestPipeline(t, testPipelineConfig())
+	store.setLanguageSettings(&models.LanguageSettings{
+		Language:        int(judgeAPI.PYTHON_3),
+		TimeMultiplier:  2.5,
+		ExtraMemory:     64000,
+		CompilerOptions: "-OO",
+	})
+
+	_, err := sp.Submit(testJob(*models.NewTestCase("1 2", "1 2")))
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+	waitForResults(t, store)
+
+	for _, s := range server.Submissions() {
+		if s.CPUTimeLimit != "2.5" || s.MemoryLimit != 192000 || s.CompilerOptions != "-OO" {
+			t.Fatalf("got time limit %s, memory limit %v and options %q", s.CPUTimeLimit, s.MemoryLimit, s.CompilerOptions)
+		}
+	}
+
+	// judge zero would turn away limits over its maximums
+	store.setLanguageSettings(&models.LanguageSettings{
+		Language:       int(judgeAPI.PYTHON_3),
+		TimeMultiplier: 100,
+		ExtraMemory:    1 << 20,
+	})
+	before := len(server.Submissions())
+	_, err = sp.Submit(testJob(*models.NewTestCase("1 2", "1 2")))
+	if err != nil {
+		t.Fatalf("error submitting job: %v", err)
+	}
+	waitForResults(t, store)
+
+	for _, s := range server.Submissions()[before:] {
+		if s.CPUTimeLimit != "15" || s.MemoryLimit != models.MAX_JUDGE_MEMORY_LIMIT {
+			t.Fatalf("got time limit %s and memory limit %v over the judge maximums", s.CPUTimeLimit, s.MemoryLimit)
+		}
+	}
+}
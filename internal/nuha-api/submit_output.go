@@ -0,0 +1,165 @@
+package nuha
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Modalessi/nuha-api/internal"
+	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
+	"github.com/Modalessi/nuha-api/internal/models"
+	"github.com/Modalessi/nuha-api/internal/repositories"
+	"github.com/google/uuid"
+)
+
+// uploaded outputs and source files are a few files, anything above this is not a honest upload
+const MAX_UPLOAD_SIZE = 32 << 20
+
+// submitOutput takes the answers of an output only problem, either as an
+// outputs_file zip or as outputs files, named 1.out, 2.out... like the
+// test case zips
+func submitOutput(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error {
+	problemId := r.URL.Query().Get("problem_id")
+	if problemId == "" {
+		respondWithError(w, 400, INVALID_QUERY_ERROR)
+		return fmt.Errorf("error, problem_id query was not provided")
+	}
+
+	id, err := uuid.Parse(problemId)
+	if err != nil {
+		respondWithError(w, 400, INVALID_ID_ERROR)
+		return err
+	}
+
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, MAX_UPLOAD_SIZE)
+
+	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
+	problem, err := pr.GetProblemInfo(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, EntityDoesNotExistError("Problem"))
+		return err
+	}
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	if models.ProblemType(problem.ProblemType) != models.OUTPUT_ONLY_PROBLEM_TYPE {
+		err := fmt.Errorf("problem %s does not take outputs, submit code instead", problemId)
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	outputs, err := readOutputs(r)
+	if err != nil {
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	testcases, err := pr.GetTestCases(id)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	expected := make(map[string]bool, len(testcases))
+	for i := range testcases {
+		expected[models.OutputFileName(i+1)] = true
+	}
+	for name := range outputs {
+		if !expected[name] {
+			err := fmt.Errorf("%s is not the output of any test, problem has %d tests", name, len(testcases))
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	userEmail, ok := r.Context().Value(USER_EMAIL_CONTEXT_KEY).(string)
+	if !ok {
+		respondWithError(w, 500, SERVER_ERROR)
+		return fmt.Errorf("error getting user email from context")
+	}
+
+	user, err := ns.UserRepo.GetUserByEmail(r.Context(), userEmail)
+	if err != nil {
+		respondWithError(w, 404, EntityDoesNotExistError("USER"))
+		return err
+	}
+
+	queue, err := ns.SubmissionsPL.QueueStatus(r.Context())
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+	if queue.Full() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(queue.RetryAfter.Seconds())))
+		respondWithError(w, 503, QUEUE_FULL_ERROR)
+		return fmt.Errorf("submission queue is full with %d jobs", queue.Depth)
+	}
+
+	files, err := judgeAPI.ZipFiles(outputs)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	submission := models.NewSubmission(problem.ID, user.ID, int(judgeAPI.PLAIN_TEXT), "")
+	createSubmissionParams := database.CreateSubmissionParams{
+		ProblemID:  problem.ID,
+		UserID:     user.ID,
+		Language:   int32(submission.LanguageID),
+		SourceCode: submission.SourceCode,
+		Status:     string(submission.Status),
+		Files:      files,
+	}
+
+	submissionID, position, err := ns.SubmissionsPL.SubmitNew(r.Context(), createSubmissionParams)
+	if err != nil {
+		respondWithError(w, 500, SERVER_ERROR)
+		return err
+	}
+
+	response := struct {
+		SubmissionID  uuid.UUID `json:"submission_id"`
+		QueuePosition int       `json:"queue_position"`
+	}{
+		SubmissionID:  submissionID,
+		QueuePosition: position,
+	}
+	respondWithJson(w, 201, &internal.JsonWrapper{Data: response})
+	return nil
+}
+
+func readOutputs(r *http.Request) (map[string][]byte, error) {
+	var outputs map[string][]byte
+
+	file, _, err := r.FormFile("outputs_file")
+	if err == nil {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		outputs, err = judgeAPI.UnzipFiles(data)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if r.MultipartForm == nil {
+			return nil, fmt.Errorf("no outputs were uploaded, send an outputs_file zip or outputs files")
+		}
+		outputs, err = readUploadedFiles(r.MultipartForm.File["outputs"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no outputs were uploaded, send an outputs_file zip or outputs files")
+	}
+	return outputs, nil
+}
@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Modalessi/nuha-api/internal"
 	"github.com/Modalessi/nuha-api/internal/database"
 	"github.com/Modalessi/nuha-api/internal/judgeAPI"
 	"github.com/Modalessi/nuha-api/internal/models"
-	submissionsPL "github.com/Modalessi/nuha-api/internal/nuha-api/submissions_pipeline"
 	"github.com/Modalessi/nuha-api/internal/repositories"
 	"github.com/google/uuid"
 )
@@ -29,18 +31,15 @@ func submitSolution(ns *NuhaServer, w http.ResponseWriter, r *http.Request) erro
 		return err
 	}
 
-	type submissionSchema struct {
-		Language int    `json:"language"`
-		Code     string `json:"code"`
-	}
 	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(w, r.Body, MAX_UPLOAD_SIZE)
 
-	submissionData := submissionSchema{}
-	err = json.NewDecoder(r.Body).Decode(&submissionData)
+	submissionData, err := readSubmission(r)
 	if err != nil {
-		respondWithError(w, 400, INVALID_JSON_ERROR)
+		respondWithError(w, 400, err)
 		return err
 	}
+	multiFile := submissionData.EntryPoint != ""
 
 	pr := repositories.NewProblemRepository(ns.DB, ns.DBQueries, r.Context())
 	problem, err := pr.GetProblemInfo(id)
@@ -53,6 +52,52 @@ func submitSolution(ns *NuhaServer, w http.ResponseWriter, r *http.Request) erro
 		return err
 	}
 
+	if models.ProblemType(problem.ProblemType) == models.OUTPUT_ONLY_PROBLEM_TYPE {
+		err := fmt.Errorf("problem %s takes outputs, submit them to /submit/output", problemId)
+		respondWithError(w, 400, err)
+		return err
+	}
+
+	// interactive and multi-file runs are put together by scripts that only know some languages
+	if (multiFile || models.ProblemType(problem.ProblemType) == models.INTERACTIVE_PROBLEM_TYPE) && !judgeAPI.SupportsScripts(judgeAPI.JudgeLanguage(submissionData.Language)) {
+		respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+		return fmt.Errorf("language %d can not be used for interactive problems or multi-file submissions", submissionData.Language)
+	}
+
+	var files []byte
+	if multiFile {
+		files, err = judgeAPI.ZipFiles(submissionData.Files)
+		if err != nil {
+			respondWithError(w, 500, SERVER_ERROR)
+			return err
+		}
+	}
+
+	if models.ProblemType(problem.ProblemType) == models.INTERACTIVE_PROBLEM_TYPE {
+		interactor, err := pr.GetInteractor(id)
+		if err != nil {
+			respondWithError(w, 500, SERVER_ERROR)
+			return err
+		}
+		if interactor == nil {
+			err := fmt.Errorf("problem %s has no interactor yet", problemId)
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	if models.ProblemType(problem.ProblemType) == models.FUNCTION_PROBLEM_TYPE {
+		harness, err := pr.GetHarness(id, submissionData.Language)
+		if err != nil {
+			respondWithError(w, 500, SERVER_ERROR)
+			return err
+		}
+		if harness == nil {
+			respondWithError(w, 400, INVALID_LANGUAGE_ERROR)
+			return fmt.Errorf("problem %s has no harness for language %d", problemId, submissionData.Language)
+		}
+	}
+
 	userEmail, ok := r.Context().Value(USER_EMAIL_CONTEXT_KEY).(string)
 	if !ok {
 		respondWithError(w, 500, SERVER_ERROR)
@@ -65,11 +110,17 @@ func submitSolution(ns *NuhaServer, w http.ResponseWriter, r *http.Request) erro
 		return err
 	}
 
-	testcases, err := pr.GetTestCases(id)
+	// turn the submission away before it has a row nobody will judge
+	queue, err := ns.SubmissionsPL.QueueStatus(r.Context())
 	if err != nil {
 		respondWithError(w, 500, SERVER_ERROR)
 		return err
 	}
+	if queue.Full() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(queue.RetryAfter.Seconds())))
+		respondWithError(w, 503, QUEUE_FULL_ERROR)
+		return fmt.Errorf("submission queue is full with %d jobs", queue.Depth)
+	}
 
 	// create the submiios here
 	submission := models.NewSubmission(problem.ID, user.ID, submissionData.Language, submissionData.Code)
@@ -81,31 +132,112 @@ func submitSolution(ns *NuhaServer, w http.ResponseWriter, r *http.Request) erro
 		Language:   int32(submission.LanguageID),
 		SourceCode: submission.SourceCode,
 		Status:     string(submission.Status),
+		Files:      files,
+		EntryPoint: submissionData.EntryPoint,
 	}
 
-	submissionDB, err := ns.DBQueries.CreateSubmission(r.Context(), *createSubmissionParams)
+	// give it to submision piplie line here, the job is loaded from the submission
+	submissionID, position, err := ns.SubmissionsPL.SubmitNew(r.Context(), *createSubmissionParams)
 	if err != nil {
 		respondWithError(w, 500, SERVER_ERROR)
-		return fmt.Errorf("error creating submission in database: %w", err)
-	}
-
-	// give it to submision piplie line here
-	submissionJob := &submissionsPL.SubmissionJob{
-		SubmissionID: submissionDB.ID,
-		Language:     judgeAPI.JudgeLanguage(submissionDB.Language),
-		Code:         submissionDB.SourceCode,
-		Timelimit:    problem.TimeLimit,
-		MemoryLimit:  problem.MemoryLimit,
-		ProblemID:    problem.ID,
-		Testcases:    models.TestCasesFromDBObjects(testcases),
+		return err
 	}
-	ns.SubmissionsPL.Submit(submissionJob)
 
 	response := struct {
-		SubmissionID uuid.UUID `json:"submission_id"`
+		SubmissionID  uuid.UUID `json:"submission_id"`
+		QueuePosition int       `json:"queue_position"`
 	}{
-		SubmissionID: submissionDB.ID,
+		SubmissionID:  submissionID,
+		QueuePosition: position,
 	}
 	respondWithJson(w, 201, &internal.JsonWrapper{Data: response})
 	return nil
 }
+
+type submissionSchema struct {
+	Language int    `json:"language"`
+	Code     string `json:"code"`
+	// set for multi-file submissions, Code is the entry point file
+	EntryPoint string            `json:"entry_point,omitempty"`
+	Files      map[string][]byte `json:"-"`
+	// only for runs, the sample tests are used when it is not given
+	Stdin *string `json:"stdin,omitempty"`
+}
+
+// readSubmission reads a json submission, or a multipart one with a
+// language, the source files as a project_file zip or as files, and the
+// entry_point of them. json submissions can have more files too
+func readSubmission(r *http.Request) (submissionSchema, error) {
+	submissionData := submissionSchema{}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err := r.ParseMultipartForm(MAX_UPLOAD_SIZE)
+		if err != nil {
+			return submissionData, err
+		}
+
+		submissionData.Language, err = strconv.Atoi(r.FormValue("language"))
+		if err != nil {
+			return submissionData, INVALID_LANGUAGE_ERROR
+		}
+		submissionData.EntryPoint = r.FormValue("entry_point")
+		if stdin, ok := r.MultipartForm.Value["stdin"]; ok && len(stdin) > 0 {
+			submissionData.Stdin = &stdin[0]
+		}
+
+		file, _, err := r.FormFile("project_file")
+		if err == nil {
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return submissionData, err
+			}
+			submissionData.Files, err = judgeAPI.UnzipFiles(data)
+			if err != nil {
+				return submissionData, err
+			}
+		} else {
+			submissionData.Files, err = readUploadedFiles(r.MultipartForm.File["files"])
+			if err != nil {
+				return submissionData, err
+			}
+		}
+	} else {
+		jsonData := struct {
+			submissionSchema
+			Files map[string]string `json:"files,omitempty"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&jsonData)
+		if err != nil {
+			return submissionData, INVALID_JSON_ERROR
+		}
+
+		submissionData = jsonData.submissionSchema
+		if len(jsonData.Files) > 0 {
+			submissionData.Files = make(map[string][]byte, len(jsonData.Files))
+			for name, content := range jsonData.Files {
+				submissionData.Files[name] = []byte(content)
+			}
+			// the code is the entry point, it is one of the files
+			if submissionData.EntryPoint != "" {
+				submissionData.Files[submissionData.EntryPoint] = []byte(submissionData.Code)
+			}
+		}
+	}
+
+	if submissionData.EntryPoint == "" {
+		if len(submissionData.Files) > 0 {
+			return submissionData, fmt.Errorf("entry_point is needed to submit more than one file")
+		}
+		return submissionData, nil
+	}
+
+	code, ok := submissionData.Files[submissionData.EntryPoint]
+	if !ok {
+		return submissionData, fmt.Errorf("entry point %s is not one of the submitted files", submissionData.EntryPoint)
+	}
+	submissionData.Code = string(code)
+	delete(submissionData.Files, submissionData.EntryPoint)
+
+	program := judgeAPI.Program{Language: judgeAPI.JudgeLanguage(submissionData.Language), Files: submissionData.Files, Entry: submissionData.EntryPoint}
+	return submissionData, program.Validate()
+}
@@ -25,12 +25,18 @@ func updateProblem(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 	}
 
 	type updateProblemSchema struct {
-		Title       *string  `json:"title,omitempty"`
-		Description *string  `json:"description,omitempty"`
-		Difficulty  *string  `json:"difficulty,omitempty"`
-		Tags        []string `json:"tags,omitempty"`
-		TimeLimit   *float64 `json:"time_limit,omitempty"`
-		MemoryLimit *float64 `json:"memory_limit,omitempty"`
+		Title             *string  `json:"title,omitempty"`
+		Description       *string  `json:"description,omitempty"`
+		Difficulty        *string  `json:"difficulty,omitempty"`
+		Tags              []string `json:"tags,omitempty"`
+		TimeLimit         *float64 `json:"time_limit,omitempty"`
+		MemoryLimit       *float64 `json:"memory_limit,omitempty"`
+		JudgingMode       *string  `json:"judging_mode,omitempty"`
+		ScoringMode       *string  `json:"scoring_mode,omitempty"`
+		ComparisonMode    *string  `json:"comparison_mode,omitempty"`
+		ComparisonEpsilon *float64 `json:"comparison_epsilon,omitempty"`
+		ProblemType       *string  `json:"problem_type,omitempty"`
+		FunctionSignature *string  `json:"function_signature,omitempty"`
 	}
 
 	defer r.Body.Close()
@@ -47,7 +53,13 @@ func updateProblem(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 		updateData.Difficulty == nil &&
 		len(updateData.Tags) == 0 &&
 		updateData.TimeLimit == nil &&
-		updateData.MemoryLimit == nil {
+		updateData.MemoryLimit == nil &&
+		updateData.JudgingMode == nil &&
+		updateData.ScoringMode == nil &&
+		updateData.ComparisonMode == nil &&
+		updateData.ComparisonEpsilon == nil &&
+		updateData.ProblemType == nil &&
+		updateData.FunctionSignature == nil {
 		respondWithError(w, 400, INVALID_JSON_ERROR)
 		return fmt.Errorf("at least one field must be provided for update")
 	}
@@ -95,6 +107,47 @@ func updateProblem(ns *NuhaServer, w http.ResponseWriter, r *http.Request) error
 	if updateData.MemoryLimit != nil {
 		problem.SetMemoryLimit(*updateData.MemoryLimit)
 	}
+	if updateData.JudgingMode != nil {
+		err := problem.SetJudgingMode(*updateData.JudgingMode)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+	if updateData.ScoringMode != nil {
+		err := problem.SetScoringMode(*updateData.ScoringMode)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	if updateData.ComparisonMode != nil {
+		err := problem.SetComparisonMode(*updateData.ComparisonMode)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+	if updateData.ComparisonEpsilon != nil {
+		err := problem.SetComparisonEpsilon(*updateData.ComparisonEpsilon)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	if updateData.ProblemType != nil {
+		err := problem.SetProblemType(*updateData.ProblemType)
+		if err != nil {
+			respondWithError(w, 400, err)
+			return err
+		}
+	}
+
+	if updateData.FunctionSignature != nil {
+		problem.SetFunctionSignature(*updateData.FunctionSignature)
+	}
 
 	err = pr.UpdateProblem(problem)
 	if err != nil {
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/models"
+)
+
+type LanguageRepository struct {
+	db        *sql.DB
+	dbQueries *database.Queries
+	ctx       context.Context
+}
+
+func NewLanguageRepository(db *sql.DB, dbQueries *database.Queries, ctx context.Context) *LanguageRepository {
+	return &LanguageRepository{
+		db:        db,
+		dbQueries: dbQueries,
+		ctx:       ctx,
+	}
+}
+
+// GetSettings returns the default settings for languages without any
+func (lr *LanguageRepository) GetSettings(language int) (*models.LanguageSettings, error) {
+
+	settings, err := lr.dbQueries.GetLanguageSettings(lr.ctx, int32(language))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultLanguageSettings(language), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error getting language %d settings: %w", language, err)
+	}
+
+	return models.LanguageSettingsFromDBObject(&settings), nil
+}
+
+func (lr *LanguageRepository) GetAllSettings() ([]models.LanguageSettings, error) {
+
+	settingsDB, err := lr.dbQueries.GetAllLanguageSettings(lr.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error getting language settings: %w", err)
+	}
+
+	settings := make([]models.LanguageSettings, len(settingsDB))
+	for i := range settingsDB {
+		settings[i] = *models.LanguageSettingsFromDBObject(&settingsDB[i])
+	}
+	return settings, nil
+}
+
+func (lr *LanguageRepository) SetSettings(settings *models.LanguageSettings) error {
+
+	setSettingsParams := database.SetLanguageSettingsParams{
+		Language:        int32(settings.Language),
+		TimeMultiplier:  settings.TimeMultiplier,
+		ExtraMemory:     settings.ExtraMemory,
+		CompilerOptions: settings.CompilerOptions,
+	}
+	_, err := lr.dbQueries.SetLanguageSettings(lr.ctx, setSettingsParams)
+	return err
+}
+
+func (lr *LanguageRepository) DeleteSettings(language int) error {
+	return lr.dbQueries.DeleteLanguageSettings(lr.ctx, int32(language))
+}
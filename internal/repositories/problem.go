@@ -3,9 +3,11 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/Modalessi/nuha-api/internal/database"
+	"github.com/Modalessi/nuha-api/internal/judgeAPI"
 	"github.com/Modalessi/nuha-api/internal/models"
 	"github.com/google/uuid"
 )
@@ -27,11 +29,17 @@ func NewProblemRepository(db *sql.DB, dbQueries *database.Queries, ctx context.C
 func (pr *ProblemRepository) StoreNewProblem(p *models.Problem) (*database.Problem, error) {
 
 	newProblemParams := database.CreateProblemParams{
-		Title:       p.Title,
-		Difficulty:  p.Difficulty,
-		Tags:        p.Tags,
-		TimeLimit:   p.Timelimit,
-		MemoryLimit: p.Memorylimit,
+		Title:             p.Title,
+		Difficulty:        p.Difficulty,
+		Tags:              p.Tags,
+		TimeLimit:         p.Timelimit,
+		MemoryLimit:       p.Memorylimit,
+		JudgingMode:       string(p.JudgingMode),
+		ScoringMode:       string(p.ScoringMode),
+		ComparisonMode:    string(p.ComparisonMode),
+		ComparisonEpsilon: p.ComparisonEpsilon,
+		ProblemType:       string(p.ProblemType),
+		FunctionSignature: p.FunctionSignature,
 	}
 
 	tx, err := pr.db.BeginTx(pr.ctx, nil)
@@ -58,16 +66,22 @@ func (pr *ProblemRepository) StoreNewProblem(p *models.Problem) (*database.Probl
 
 	testCasesStdins := make([]string, len(p.Testcases))
 	testCasesExpectedOutputs := make([]string, len(p.Testcases))
+	testCasesGroups := make([]int32, len(p.Testcases))
+	testCasesVisibilities := make([]string, len(p.Testcases))
 
 	for i := range p.Testcases {
 		testCasesStdins[i] = p.Testcases[i].Stdin
 		testCasesExpectedOutputs[i] = p.Testcases[i].ExpectedOutput
+		testCasesGroups[i] = int32(p.Testcases[i].Group)
+		testCasesVisibilities[i] = string(p.Testcases[i].GetVisibility())
 	}
 
 	addTestCasesParams := database.CreateTestCasesParams{
 		ProblemID:       dbProblem.ID,
 		Stdins:          testCasesStdins,
 		ExpectedOutputs: testCasesExpectedOutputs,
+		GroupNumbers:    testCasesGroups,
+		Visibilities:    testCasesVisibilities,
 	}
 	_, err = qtx.CreateTestCases(pr.ctx, addTestCasesParams)
 	if err != nil {
@@ -126,20 +140,43 @@ func (pr *ProblemRepository) GetTestCases(problemId uuid.UUID) ([]database.TestC
 	return dbTestCases, nil
 }
 
+// GetSampleTestCases returns the tests anyone can see
+func (pr *ProblemRepository) GetSampleTestCases(problemId uuid.UUID) ([]models.Testcase, error) {
+
+	dbTestCases, err := pr.GetTestCases(problemId)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []models.Testcase{}
+	for _, tc := range models.TestCasesFromDBObjects(dbTestCases) {
+		if tc.IsSample() {
+			samples = append(samples, tc)
+		}
+	}
+	return samples, nil
+}
+
 func (pr *ProblemRepository) AddNewTestCases(problemId uuid.UUID, testcases ...models.Testcase) error {
 
 	testCasesStdins := make([]string, len(testcases))
 	testCasesExpectedOutputs := make([]string, len(testcases))
+	testCasesGroups := make([]int32, len(testcases))
+	testCasesVisibilities := make([]string, len(testcases))
 
 	for i := range testcases {
 		testCasesStdins[i] = testcases[i].Stdin
 		testCasesExpectedOutputs[i] = testcases[i].ExpectedOutput
+		testCasesGroups[i] = int32(testcases[i].Group)
+		testCasesVisibilities[i] = string(testcases[i].GetVisibility())
 	}
 
 	addTestCasesParams := database.CreateTestCasesParams{
 		ProblemID:       problemId,
 		Stdins:          testCasesStdins,
 		ExpectedOutputs: testCasesExpectedOutputs,
+		GroupNumbers:    testCasesGroups,
+		Visibilities:    testCasesVisibilities,
 	}
 	_, err := pr.dbQueries.CreateTestCases(pr.ctx, addTestCasesParams)
 
@@ -150,6 +187,176 @@ func (pr *ProblemRepository) AddNewTestCases(problemId uuid.UUID, testcases ...m
 	return nil
 }
 
+func (pr *ProblemRepository) GetTestGroups(problemId uuid.UUID) ([]models.TestGroup, error) {
+
+	dbTestGroups, err := pr.dbQueries.GetTestGroups(pr.ctx, problemId)
+	if err != nil {
+		return nil, err
+	}
+	return models.TestGroupsFromDBObjects(dbTestGroups), nil
+}
+
+// SetTestGroups replaces all the test groups of a problem
+func (pr *ProblemRepository) SetTestGroups(problemId uuid.UUID, groups ...models.TestGroup) error {
+
+	tx, err := pr.db.BeginTx(pr.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	txq := pr.dbQueries.WithTx(tx)
+
+	err = txq.DeleteTestGroups(pr.ctx, problemId)
+	if err != nil {
+		return fmt.Errorf("error deleting problem %s test groups: %w", problemId, err)
+	}
+
+	for _, g := range groups {
+		dependencies := make([]int32, len(g.Dependencies))
+		for i, d := range g.Dependencies {
+			dependencies[i] = int32(d)
+		}
+
+		createTestGroupParams := database.CreateTestGroupParams{
+			ProblemID:    problemId,
+			Number:       int32(g.Number),
+			Points:       g.Points,
+			Dependencies: dependencies,
+		}
+		_, err = txq.CreateTestGroup(pr.ctx, createTestGroupParams)
+		if err != nil {
+			return fmt.Errorf("error creating test group %d: %w", g.Number, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetChecker returns nil when the problem has no checker
+func (pr *ProblemRepository) GetChecker(problemId uuid.UUID) (*database.ProblemChecker, error) {
+
+	checker, err := pr.dbQueries.GetProblemChecker(pr.ctx, problemId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error getting problem %s checker: %w", problemId, err)
+	}
+
+	return &checker, nil
+}
+
+// SetChecker replaces the checker of a problem, files are what the checker
+// source needs next to it to compile
+func (pr *ProblemRepository) SetChecker(problemId uuid.UUID, language int, sourceCode string, files map[string][]byte) error {
+
+	zipped, err := judgeAPI.ZipFiles(files)
+	if err != nil {
+		return fmt.Errorf("error zipping checker files: %w", err)
+	}
+
+	setCheckerParams := database.SetProblemCheckerParams{
+		ProblemID:  problemId,
+		Language:   int32(language),
+		SourceCode: sourceCode,
+		Files:      zipped,
+	}
+	_, err = pr.dbQueries.SetProblemChecker(pr.ctx, setCheckerParams)
+	return err
+}
+
+func (pr *ProblemRepository) DeleteChecker(problemId uuid.UUID) error {
+	return pr.dbQueries.DeleteProblemChecker(pr.ctx, problemId)
+}
+
+// GetInteractor returns nil when the problem has no interactor
+func (pr *ProblemRepository) GetInteractor(problemId uuid.UUID) (*database.ProblemInteractor, error) {
+
+	interactor, err := pr.dbQueries.GetProblemInteractor(pr.ctx, problemId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error getting problem %s interactor: %w", problemId, err)
+	}
+
+	return &interactor, nil
+}
+
+func (pr *ProblemRepository) SetInteractor(problemId uuid.UUID, language int, sourceCode string, files map[string][]byte) error {
+
+	zipped, err := judgeAPI.ZipFiles(files)
+	if err != nil {
+		return fmt.Errorf("error zipping interactor files: %w", err)
+	}
+
+	setInteractorParams := database.SetProblemInteractorParams{
+		ProblemID:  problemId,
+		Language:   int32(language),
+		SourceCode: sourceCode,
+		Files:      zipped,
+	}
+	_, err = pr.dbQueries.SetProblemInteractor(pr.ctx, setInteractorParams)
+	return err
+}
+
+func (pr *ProblemRepository) DeleteInteractor(problemId uuid.UUID) error {
+	return pr.dbQueries.DeleteProblemInteractor(pr.ctx, problemId)
+}
+
+// GetHarness returns nil when the problem has no harness for language
+func (pr *ProblemRepository) GetHarness(problemId uuid.UUID, language int) (*models.Harness, error) {
+
+	getHarnessParams := database.GetProblemHarnessParams{
+		ProblemID: problemId,
+		Language:  int32(language),
+	}
+	harness, err := pr.dbQueries.GetProblemHarness(pr.ctx, getHarnessParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error getting problem %s harness: %w", problemId, err)
+	}
+
+	return models.HarnessFromDBObject(&harness), nil
+}
+
+func (pr *ProblemRepository) GetHarnesses(problemId uuid.UUID) ([]models.Harness, error) {
+
+	harnessesDB, err := pr.dbQueries.GetProblemHarnesses(pr.ctx, problemId)
+	if err != nil {
+		return nil, fmt.Errorf("database error getting problem %s harnesses: %w", problemId, err)
+	}
+
+	harnesses := make([]models.Harness, len(harnessesDB))
+	for i := range harnessesDB {
+		harnesses[i] = *models.HarnessFromDBObject(&harnessesDB[i])
+	}
+	return harnesses, nil
+}
+
+func (pr *ProblemRepository) SetHarness(problemId uuid.UUID, harness *models.Harness) error {
+
+	setHarnessParams := database.SetProblemHarnessParams{
+		ProblemID:   problemId,
+		Language:    int32(harness.Language),
+		Template:    harness.Template,
+		StarterCode: harness.StarterCode,
+	}
+	_, err := pr.dbQueries.SetProblemHarness(pr.ctx, setHarnessParams)
+	return err
+}
+
+func (pr *ProblemRepository) DeleteHarness(problemId uuid.UUID, language int) error {
+	deleteHarnessParams := database.DeleteProblemHarnessParams{
+		ProblemID: problemId,
+		Language:  int32(language),
+	}
+	return pr.dbQueries.DeleteProblemHarness(pr.ctx, deleteHarnessParams)
+}
+
 func (pr *ProblemRepository) DeleteProblem(problemId uuid.UUID) (*database.Problem, error) {
 
 	tx, err := pr.db.BeginTx(pr.ctx, nil)
@@ -193,12 +400,18 @@ func (pr *ProblemRepository) UpdateProblem(problem *models.Problem) error {
 	txq := pr.dbQueries.WithTx(tx)
 
 	updateProblemParams := database.UpdateProblemParams{
-		ID:          *problem.ID,
-		Title:       problem.Title,
-		Difficulty:  problem.Difficulty,
-		Tags:        problem.Tags,
-		TimeLimit:   problem.Timelimit,
-		MemoryLimit: problem.Memorylimit,
+		ID:                *problem.ID,
+		Title:             problem.Title,
+		Difficulty:        problem.Difficulty,
+		Tags:              problem.Tags,
+		TimeLimit:         problem.Timelimit,
+		MemoryLimit:       problem.Memorylimit,
+		JudgingMode:       string(problem.JudgingMode),
+		ScoringMode:       string(problem.ScoringMode),
+		ComparisonMode:    string(problem.ComparisonMode),
+		ComparisonEpsilon: problem.ComparisonEpsilon,
+		ProblemType:       string(problem.ProblemType),
+		FunctionSignature: problem.FunctionSignature,
 	}
 	_, err = txq.UpdateProblem(pr.ctx, updateProblemParams)
 	if err != nil {
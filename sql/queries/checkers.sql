@@ -0,0 +1,26 @@
+-- name: SetProblemChecker :one
+INSERT INTO problem_checkers (
+    problem_id,
+    language,
+    source_code,
+    files
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (problem_id) DO UPDATE SET
+    language = EXCLUDED.language,
+    source_code = EXCLUDED.source_code,
+    files = EXCLUDED.files,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: GetProblemChecker :one
+SELECT * FROM problem_checkers WHERE problem_id = $1;
+
+
+-- name: DeleteProblemChecker :exec
+DELETE FROM problem_checkers WHERE problem_id = $1;
@@ -0,0 +1,29 @@
+-- name: SetProblemHarness :one
+INSERT INTO problem_harnesses (
+    problem_id,
+    language,
+    template,
+    starter_code
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (problem_id, language) DO UPDATE SET
+    template = EXCLUDED.template,
+    starter_code = EXCLUDED.starter_code,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: GetProblemHarness :one
+SELECT * FROM problem_harnesses WHERE problem_id = $1 AND language = $2;
+
+
+-- name: GetProblemHarnesses :many
+SELECT * FROM problem_harnesses WHERE problem_id = $1 ORDER BY language;
+
+
+-- name: DeleteProblemHarness :exec
+DELETE FROM problem_harnesses WHERE problem_id = $1 AND language = $2;
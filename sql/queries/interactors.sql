@@ -0,0 +1,26 @@
+-- name: SetProblemInteractor :one
+INSERT INTO problem_interactors (
+    problem_id,
+    language,
+    source_code,
+    files
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (problem_id) DO UPDATE SET
+    language = EXCLUDED.language,
+    source_code = EXCLUDED.source_code,
+    files = EXCLUDED.files,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: GetProblemInteractor :one
+SELECT * FROM problem_interactors WHERE problem_id = $1;
+
+
+-- name: DeleteProblemInteractor :exec
+DELETE FROM problem_interactors WHERE problem_id = $1;
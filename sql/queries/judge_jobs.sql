@@ -0,0 +1,123 @@
+-- name: CreateJudgeJob :one
+INSERT INTO judge_jobs (
+    submission_id,
+    user_id
+)
+SELECT s.id, s.user_id FROM submissions s WHERE s.id = $1
+RETURNING *;
+
+
+-- name: GetQueuedJudgeJobHeads :many
+SELECT submission_id, user_id, created_at FROM (
+    SELECT submission_id, user_id, created_at,
+        ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at) AS user_position
+    FROM judge_jobs
+    WHERE state = 'QUEUED' AND (lease_expires_at IS NULL OR lease_expires_at < now())
+) heads
+WHERE user_position = 1
+ORDER BY created_at
+LIMIT $1;
+
+
+-- name: CountInFlightJudgeJobsByUser :many
+SELECT user_id, COUNT(*) AS in_flight FROM judge_jobs
+WHERE state = 'SUBMITTED' OR (state = 'QUEUED' AND lease_expires_at > now())
+GROUP BY user_id;
+
+
+-- name: ClaimQueuedJudgeJob :one
+UPDATE judge_jobs SET
+    attempts = attempts + 1,
+    lease_expires_at = now() + make_interval(secs => @lease_seconds::float8),
+    updated_at = now()
+WHERE id = (
+    SELECT id FROM judge_jobs
+    WHERE submission_id = @submission_id AND state = 'QUEUED' AND (lease_expires_at IS NULL OR lease_expires_at < now())
+    FOR UPDATE SKIP LOCKED
+) RETURNING *;
+
+
+-- name: ClaimStalledJudgeJobs :many
+UPDATE judge_jobs SET
+    lease_expires_at = now() + make_interval(secs => @lease_seconds::float8),
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM judge_jobs
+    WHERE state = 'SUBMITTED' AND lease_expires_at < now()
+    FOR UPDATE SKIP LOCKED
+) RETURNING *;
+
+
+-- name: SetJudgeJobSubmitted :one
+UPDATE judge_jobs SET
+    state = 'SUBMITTED',
+    tokens = @tokens::text[],
+    lease_expires_at = now() + make_interval(secs => @lease_seconds::float8),
+    last_error = '',
+    updated_at = now()
+WHERE submission_id = @submission_id RETURNING *;
+
+
+-- name: SetJudgeJobError :one
+UPDATE judge_jobs SET
+    last_error = $2,
+    last_response = $3,
+    updated_at = now()
+WHERE submission_id = $1 RETURNING *;
+
+
+-- name: RenewJudgeJobsLease :exec
+UPDATE judge_jobs SET
+    lease_expires_at = now() + make_interval(secs => @lease_seconds::float8),
+    updated_at = now()
+WHERE submission_id = ANY(@submission_ids::uuid[]) AND state = 'SUBMITTED';
+
+
+-- name: SetJudgeJobState :one
+UPDATE judge_jobs SET
+    state = $2,
+    lease_expires_at = NULL,
+    updated_at = now()
+WHERE submission_id = $1 RETURNING *;
+
+
+-- name: GetJudgeJobBySubmissionID :one
+SELECT * FROM judge_jobs WHERE submission_id = $1;
+
+
+-- name: GetDeadJudgeJobs :many
+SELECT * FROM judge_jobs WHERE state = 'DEAD' ORDER BY updated_at DESC OFFSET $1 LIMIT $2;
+
+
+-- name: RequeueDeadJudgeJob :one
+UPDATE judge_jobs SET
+    state = 'QUEUED',
+    attempts = 0,
+    lease_expires_at = NULL,
+    updated_at = now()
+WHERE submission_id = $1 AND state = 'DEAD' RETURNING *;
+
+
+-- name: GetPendingSubmissionsForRecovery :many
+SELECT
+    s.id,
+    j.state AS job_state,
+    ARRAY(
+        SELECT r.judge_token FROM submission_results r
+        WHERE r.submission_id = s.id
+        ORDER BY r.created_at
+    )::text[] AS result_tokens
+FROM submissions s
+LEFT JOIN judge_jobs j ON j.submission_id = s.id
+WHERE s.status = 'PENDING';
+
+
+-- name: CountQueuedJudgeJobs :one
+SELECT COUNT(*) FROM judge_jobs WHERE state = 'QUEUED';
+
+
+-- name: GetJudgeJobQueuePosition :one
+SELECT COUNT(*) FROM judge_jobs
+WHERE state = 'QUEUED' AND created_at <= (
+    SELECT j.created_at FROM judge_jobs j WHERE j.submission_id = $1
+);
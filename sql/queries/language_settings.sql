@@ -0,0 +1,30 @@
+-- name: SetLanguageSettings :one
+INSERT INTO language_settings (
+    language,
+    time_multiplier,
+    extra_memory,
+    compiler_options
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (language) DO UPDATE SET
+    time_multiplier = EXCLUDED.time_multiplier,
+    extra_memory = EXCLUDED.extra_memory,
+    compiler_options = EXCLUDED.compiler_options,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: GetLanguageSettings :one
+SELECT * FROM language_settings WHERE language = $1;
+
+
+-- name: GetAllLanguageSettings :many
+SELECT * FROM language_settings ORDER BY language;
+
+
+-- name: DeleteLanguageSettings :exec
+DELETE FROM language_settings WHERE language = $1;
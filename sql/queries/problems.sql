@@ -12,13 +12,25 @@ INSERT INTO problems (
     difficulty,
     tags,
     time_limit,
-    memory_limit
+    memory_limit,
+    judging_mode,
+    scoring_mode,
+    comparison_mode,
+    comparison_epsilon,
+    problem_type,
+    function_signature
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
 ) RETURNING *;
 
 
@@ -33,6 +45,12 @@ UPDATE problems SET
     tags = $4,
     time_limit = $5,
     memory_limit = $6,
+    judging_mode = $7,
+    scoring_mode = $8,
+    comparison_mode = $9,
+    comparison_epsilon = $10,
+    problem_type = $11,
+    function_signature = $12,
     updated_at = now()
 WHERE id = $1 RETURNING *;
 
@@ -69,19 +87,25 @@ WITH numbered_arrays AS (
             COALESCE((SELECT MAX(number) FROM test_cases WHERE problem_id = $1), 0) + array_length(@stdins::TEXT[], 1)
         ) as num,
         unnest(@stdins::TEXT[]) as in_data,
-        unnest(@expected_outputs::TEXT[]) as out_data
+        unnest(@expected_outputs::TEXT[]) as out_data,
+        unnest(@group_numbers::INTEGER[]) as group_data,
+        unnest(@visibilities::VARCHAR[]) as visibility_data
 )
 INSERT INTO test_cases (
     problem_id,
     number,
     stdin,
-    expected_output
+    expected_output,
+    group_number,
+    visibility
 ) 
 SELECT 
     $1,
     num,
     in_data,
-    out_data
+    out_data,
+    group_data,
+    visibility_data
 FROM numbered_arrays
 RETURNING *;
 
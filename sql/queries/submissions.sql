@@ -4,13 +4,17 @@ INSERT INTO submissions (
     user_id,
     language,
     source_code,
-    status
+    status,
+    files,
+    entry_point
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7
 ) RETURNING *;
 
 
@@ -76,7 +80,15 @@ INSERT INTO submission_results (
     status_id,
     time_used,
     memory_used,
-    judge_response
+    judge_response,
+    query_log,
+    test_number,
+    compile_output,
+    stderr,
+    message,
+    exit_code,
+    exit_signal,
+    visibility
 ) 
 VALUES (
     gen_random_uuid(),
@@ -88,7 +100,15 @@ VALUES (
     unnest(@statuses::integer[]),
     unnest(@times::text[]),
     unnest(@memories::float8[]),
-    unnest(@responses::bytea[])
+    unnest(@responses::bytea[]),
+    unnest(@query_logs::text[]),
+    unnest(@test_numbers::integer[]),
+    unnest(@compile_outputs::text[]),
+    unnest(@stderrs::text[]),
+    unnest(@messages::text[]),
+    unnest(@exit_codes::integer[]),
+    unnest(@exit_signals::integer[]),
+    unnest(@visibilities::VARCHAR[])
 )
 RETURNING *;
 
@@ -97,7 +117,7 @@ RETURNING *;
 SELECT * FROM submission_results WHERE id = $1;
 
 -- name: GetSubmissionResultsBySubmissionID :many
-SELECT * FROM submission_results WHERE submission_id = $1 OFFSET $2 LIMIT $3;
+SELECT * FROM submission_results WHERE submission_id = $1 ORDER BY test_number OFFSET $2 LIMIT $3;
 
 
 -- name: UpdateSubmissionResult :one
@@ -116,4 +136,12 @@ WHERE id = $1 RETURNING *;
 UPDATE submission_results SET
     status_id = $2,
     updated_at = now()
-WHERE id = $1 RETURNING *;
\ No newline at end of file
+WHERE id = $1 RETURNING *;
+
+-- name: UpdateSubmissionVerdict :one
+UPDATE submissions SET
+    status = $2,
+    failed_test = $3,
+    score = $4,
+    updated_at = now()
+WHERE id = $1 RETURNING *;
@@ -0,0 +1,20 @@
+-- name: CreateTestGroup :one
+INSERT INTO test_groups (
+    problem_id,
+    number,
+    points,
+    dependencies
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+) RETURNING *;
+
+
+-- name: GetTestGroups :many
+SELECT * FROM test_groups WHERE problem_id = $1 ORDER BY number;
+
+
+-- name: DeleteTestGroups :exec
+DELETE FROM test_groups WHERE problem_id = $1;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE judge_jobs ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+UPDATE judge_jobs j SET user_id = s.user_id FROM submissions s WHERE s.id = j.submission_id;
+ALTER TABLE judge_jobs ALTER COLUMN user_id SET NOT NULL;
+
+CREATE INDEX idx_judge_jobs_user_id_state ON judge_jobs(user_id, state);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_judge_jobs_user_id_state;
+ALTER TABLE judge_jobs DROP COLUMN user_id;
+-- +goose StatementEnd
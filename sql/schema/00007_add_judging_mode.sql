@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems ADD COLUMN judging_mode VARCHAR(32) NOT NULL DEFAULT 'ALL_TESTS';
+
+-- the number of the first test that was not accepted, NULL when every test passed
+ALTER TABLE submissions ADD COLUMN failed_test INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submissions DROP COLUMN failed_test;
+ALTER TABLE problems DROP COLUMN judging_mode;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE test_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    problem_id UUID NOT NULL REFERENCES problems(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    points FLOAT NOT NULL,
+    -- numbers of the groups that must be fully passed for this one to score
+    dependencies INTEGER[] NOT NULL DEFAULT '{}',
+
+    UNIQUE(problem_id, number)
+);
+
+-- 0 is for tests outside any group, they are judged but never scored
+ALTER TABLE test_cases ADD COLUMN group_number INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE problems ADD COLUMN scoring_mode VARCHAR(32) NOT NULL DEFAULT 'SUM';
+
+-- NULL for problems without test groups
+ALTER TABLE submissions ADD COLUMN score FLOAT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submissions DROP COLUMN score;
+ALTER TABLE problems DROP COLUMN scoring_mode;
+ALTER TABLE test_cases DROP COLUMN group_number;
+DROP TABLE test_groups;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE problem_checkers (
+    problem_id UUID PRIMARY KEY REFERENCES problems(id) ON DELETE CASCADE,
+    language INTEGER NOT NULL,
+    source_code TEXT NOT NULL,
+    -- zip of the files the checker compiles with, like testlib.h
+    files bytea NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE problem_checkers;
+-- +goose StatementEnd
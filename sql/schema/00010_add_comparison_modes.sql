@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- outputs are compared by nuha, the default forgives trailing whitespace like judge zero did
+ALTER TABLE problems ADD COLUMN comparison_mode VARCHAR(32) NOT NULL DEFAULT 'TRAILING_WHITESPACE';
+ALTER TABLE problems ADD COLUMN comparison_epsilon FLOAT NOT NULL DEFAULT 0.000001;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems DROP COLUMN comparison_epsilon;
+ALTER TABLE problems DROP COLUMN comparison_mode;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems ADD COLUMN problem_type VARCHAR(32) NOT NULL DEFAULT 'STANDARD';
+
+CREATE TABLE problem_interactors (
+    problem_id UUID PRIMARY KEY REFERENCES problems(id) ON DELETE CASCADE,
+    language INTEGER NOT NULL,
+    source_code TEXT NOT NULL,
+    -- zip of the files the interactor compiles with, like testlib.h
+    files bytea NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- what the solution and the interactor said to each other
+ALTER TABLE submission_results ADD COLUMN query_log TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submission_results DROP COLUMN query_log;
+DROP TABLE problem_interactors;
+ALTER TABLE problems DROP COLUMN problem_type;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems ADD COLUMN function_signature TEXT NOT NULL DEFAULT '';
+
+-- the driver code a submitted function is put into, one per language
+CREATE TABLE problem_harnesses (
+    problem_id UUID NOT NULL REFERENCES problems(id) ON DELETE CASCADE,
+    language INTEGER NOT NULL,
+    template TEXT NOT NULL,
+    starter_code TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (problem_id, language)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE problem_harnesses;
+ALTER TABLE problems DROP COLUMN function_signature;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- zip of the files of submissions that are more than one source file
+ALTER TABLE submissions ADD COLUMN files bytea NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submissions DROP COLUMN files;
+-- +goose StatementEnd
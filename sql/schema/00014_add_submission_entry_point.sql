@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the file of a multi-file submission that is run, its code is source_code
+ALTER TABLE submissions ADD COLUMN entry_point VARCHAR(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submissions DROP COLUMN entry_point;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SAMPLE tests are shown with the problem, HIDDEN ones only to admins
+-- existing tests stay HIDDEN until an admin marks their samples
+ALTER TABLE test_cases ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT 'HIDDEN';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE test_cases DROP COLUMN visibility;
+-- +goose StatementEnd
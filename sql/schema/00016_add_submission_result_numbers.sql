@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- number of the test case the result is for, 0 for results saved before it
+ALTER TABLE submission_results ADD COLUMN test_number INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submission_results DROP COLUMN test_number;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- what judge zero said about the run besides stdout, capped before saving
+ALTER TABLE submission_results ADD COLUMN compile_output TEXT NOT NULL DEFAULT '';
+ALTER TABLE submission_results ADD COLUMN stderr TEXT NOT NULL DEFAULT '';
+ALTER TABLE submission_results ADD COLUMN message TEXT NOT NULL DEFAULT '';
+ALTER TABLE submission_results ADD COLUMN exit_code INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE submission_results ADD COLUMN exit_signal INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submission_results DROP COLUMN exit_signal;
+ALTER TABLE submission_results DROP COLUMN exit_code;
+ALTER TABLE submission_results DROP COLUMN message;
+ALTER TABLE submission_results DROP COLUMN stderr;
+ALTER TABLE submission_results DROP COLUMN compile_output;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- how a language is judged compared to the problem limits, languages
+-- without a row get the limits as they are
+CREATE TABLE language_settings (
+    language INTEGER PRIMARY KEY,
+    time_multiplier FLOAT NOT NULL DEFAULT 1,
+    -- KB on top of the problem memory limit
+    extra_memory FLOAT NOT NULL DEFAULT 0,
+    compiler_options TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- python 2.7, 3.8, 3.11, 3.12 and java
+INSERT INTO language_settings (language, time_multiplier, extra_memory) VALUES
+    (70, 3, 0),
+    (71, 3, 0),
+    (92, 3, 0),
+    (100, 3, 0),
+    (62, 2, 64000);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE language_settings;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the visibility of the test when the result was judged, tests can be
+-- replaced after. results from before are kept hidden
+ALTER TABLE submission_results ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT 'HIDDEN';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE submission_results DROP COLUMN visibility;
+-- +goose StatementEnd